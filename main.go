@@ -24,19 +24,33 @@
 // The plugin can be configured with parameters:
 //
 //	protoc --go_out=. --go-hz_out=. --go-hz_opt=verbose=true,out_dir=. your.proto
+//
+// It can also run without protoc, like the hz tool:
+//
+//	protoc-gen-go-hz new --idl your.proto --module github.com/example/project
+//	protoc-gen-go-hz update --idl your.proto
 package main
 
 import (
 	"os"
 
+	"github.com/ca-x/protoc-gen-go-hz/pkg/cli"
 	"github.com/ca-x/protoc-gen-go-hz/pkg/plugin"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
 )
 
 // main is the entry point for the protoc-gen-go-hz plugin.
 // It uses the protogen framework to handle protobuf compilation pipeline integration.
 func main() {
+	// protoc 调用插件时不带参数，并通过 stdin 传入请求；
+	// 带参数或在终端中直接运行时进入命令行模式
+	if len(os.Args) > 1 || stdinIsTerminal() {
+		os.Exit(cli.Main(os.Args[1:]))
+	}
+
 	// 使用 protogen 框架的标准插件入口点
 	// protogen.Options{}.Run() 会自动处理：
 	// 1. 从 stdin 读取 CodeGeneratorRequest
@@ -44,6 +58,12 @@ func main() {
 	// 3. 调用插件的处理函数
 	// 4. 将 CodeGeneratorResponse 写入 stdout
 	protogen.Options{}.Run(func(gen *protogen.Plugin) error {
+		// 声明支持 proto3 optional 与 editions，否则 protoc 会拒绝使用这些特性的文件
+		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL |
+			pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS)
+		gen.SupportedEditionsMinimum = descriptorpb.Edition_EDITION_PROTO2
+		gen.SupportedEditionsMaximum = descriptorpb.Edition_EDITION_2023
+
 		// 创建 HZ 插件实例，封装 protogen.Plugin
 		hzPlugin := plugin.NewHZPlugin(gen)
 
@@ -55,3 +75,9 @@ func main() {
 		return hzPlugin.Run()
 	})
 }
+
+// stdinIsTerminal 判断 stdin 是否为终端，即没有通过 protoc 调用
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
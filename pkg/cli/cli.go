@@ -0,0 +1,403 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package cli 提供不依赖protoc的独立命令行模式，用法与hz的new/update命令一致：
+//
+//	protoc-gen-go-hz new --idl api.proto --module github.com/example/project
+//	protoc-gen-go-hz update --idl api.proto
+//
+// proto文件由纯Go实现的protocompile在进程内编译，随后依次运行protoc-gen-go
+// 生成模型代码、运行HZ插件生成HTTP代码，并将结果写入磁盘。
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/cloudwego/hertz/cmd/hz/meta"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/ca-x/protoc-gen-go-hz/pkg/config"
+	"github.com/ca-x/protoc-gen-go-hz/pkg/generator"
+	"github.com/ca-x/protoc-gen-go-hz/pkg/plugin"
+	"github.com/ca-x/protoc-gen-go-hz/pkg/version"
+)
+
+const usage = `Usage:
+  protoc-gen-go-hz new    --idl <file.proto> [--module <go module>] [options]
+  protoc-gen-go-hz update --idl <file.proto> [options]
+  protoc-gen-go-hz version
+
+Without arguments the program runs as a protoc plugin, reading a
+CodeGeneratorRequest from stdin.
+
+Options:
+`
+
+// stringList 可重复的字符串参数
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// options 命令行参数
+type options struct {
+	idls       stringList
+	protoPaths stringList
+	opts       stringList
+	module     string
+	outDir     string
+	handlerDir string
+	modelDir   string
+	routerDir  string
+	clientDir  string
+	verbose    bool
+}
+
+// Main 运行命令行模式，返回进程退出码
+func Main(args []string) int {
+	if err := Run(args, os.Stdout, os.Stderr); err != nil {
+		// 帮助信息已由flag输出
+		if errors.Is(err, flag.ErrHelp) {
+			return 2
+		}
+		fmt.Fprintln(os.Stderr, "protoc-gen-go-hz:", err)
+		return 1
+	}
+	return 0
+}
+
+// Run 执行子命令 new、update 或 version
+func Run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		newFlagSet("", &options{}, stderr).PrintDefaults()
+		return flag.ErrHelp
+	}
+
+	cmd := args[0]
+	switch cmd {
+	case "version", "--version", "-version":
+		fmt.Fprintln(stdout, version.Version)
+		return nil
+	case "help", "-h", "--help", "-help":
+		fmt.Fprint(stdout, usage)
+		newFlagSet("", &options{}, stdout).PrintDefaults()
+		return nil
+	case meta.CmdNew, meta.CmdUpdate:
+	default:
+		fmt.Fprint(stderr, usage)
+		newFlagSet("", &options{}, stderr).PrintDefaults()
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+
+	opts := &options{}
+	fs := newFlagSet(cmd, opts, stderr)
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	// 与hz一致，允许把idl文件直接写在参数末尾
+	opts.idls = append(opts.idls, fs.Args()...)
+	if len(opts.idls) == 0 {
+		return fmt.Errorf("%s: no idl given, use --idl <file.proto>", cmd)
+	}
+	return generate(cmd, opts)
+}
+
+func newFlagSet(name string, opts *options, output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.Var(&opts.idls, "idl", "proto file to generate code for, repeatable")
+	fs.Var(&opts.protoPaths, "proto_path", "directory to search for imports, repeatable (default: the directory of each idl)")
+	fs.Var(&opts.protoPaths, "I", "shorthand for --proto_path")
+	fs.Var(&opts.opts, "opt", "plugin parameter key=value, as with --go-hz_opt, repeatable")
+	fs.StringVar(&opts.module, "module", "", "go module of the project (default: from go.mod in out_dir, else from go_package)")
+	fs.StringVar(&opts.outDir, "out_dir", ".", "project directory")
+	fs.StringVar(&opts.handlerDir, "handler_dir", "", "handler directory (default \"biz/handler\")")
+	fs.StringVar(&opts.modelDir, "model_dir", "", "model directory (default \"biz/model\")")
+	fs.StringVar(&opts.routerDir, "router_dir", "", "router directory (default \"biz/router\")")
+	fs.StringVar(&opts.clientDir, "client_dir", "", "client directory, clients are generated when set")
+	fs.BoolVar(&opts.verbose, "verbose", false, "enable verbose output")
+	return fs
+}
+
+// generate 编译proto文件，生成模型与HTTP代码并写入out_dir
+func generate(cmd string, opts *options) error {
+	files, err := compile(opts)
+	if err != nil {
+		return err
+	}
+
+	module := opts.module
+	if module == "" {
+		module = moduleFromGoMod(opts.outDir)
+	}
+	if module == "" {
+		for _, fd := range files {
+			if opts.isIDL(fd.GetName()) && fd.GetOptions().GetGoPackage() != "" {
+				module = config.ModuleFromGoPackage(fd.GetOptions().GetGoPackage())
+				break
+			}
+		}
+	}
+	if module == "" {
+		return fmt.Errorf("cannot determine the go module: pass --module, or set go_package in %s", opts.idls[0])
+	}
+
+	// 没有go_package的文件与hz一样放在 module/model_dir/<proto包> 下
+	modelDir := opts.modelDir
+	if modelDir == "" {
+		modelDir = meta.ModelDir
+	}
+	for _, fd := range files {
+		if fd.GetOptions().GetGoPackage() != "" {
+			continue
+		}
+		if fd.Options == nil {
+			fd.Options = &descriptorpb.FileOptions{}
+		}
+		goPackage := module + "/" + filepath.ToSlash(modelDir)
+		if pkg := fd.GetPackage(); pkg != "" {
+			goPackage += "/" + strings.ReplaceAll(pkg, ".", "/")
+		}
+		fd.Options.GoPackage = proto.String(goPackage)
+	}
+
+	var toGenerate []string
+	for _, fd := range files {
+		if opts.isIDL(fd.GetName()) {
+			toGenerate = append(toGenerate, fd.GetName())
+		}
+	}
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: toGenerate,
+		ProtoFile:      files,
+	}
+
+	// 模型代码：protoc-gen-go 以 module 参数输出相对于模块根目录的路径
+	modelReq := proto.Clone(req).(*pluginpb.CodeGeneratorRequest)
+	modelReq.Parameter = proto.String("module=" + module)
+	modelResp, err := plugin.GenerateModels(modelReq)
+	if err != nil {
+		return err
+	}
+
+	// HTTP代码：与protoc调用插件时相同，参数以逗号分隔
+	params := []string{"cmd_type=" + cmd, "out_dir=" + opts.outDir, "go_module=" + module}
+	for _, p := range []struct{ key, value string }{
+		{"handler_dir", opts.handlerDir},
+		{"model_dir", opts.modelDir},
+		{"router_dir", opts.routerDir},
+		{"client_dir", opts.clientDir},
+	} {
+		if p.value != "" {
+			params = append(params, p.key+"="+p.value)
+		}
+	}
+	if opts.verbose {
+		params = append(params, "verbose=true")
+	}
+	// 与hz new一致，out_dir中没有go.mod时一并生成
+	if cmd == meta.CmdNew && !fileExists(filepath.Join(opts.outDir, "go.mod")) {
+		params = append(params, "need_go_mod=true")
+	}
+	params = append(params, opts.opts...)
+	req.Parameter = proto.String(strings.Join(params, ","))
+
+	gen, err := protogen.Options{}.New(req)
+	if err != nil {
+		return err
+	}
+	hzPlugin := plugin.NewHZPlugin(gen)
+	var models []*generator.GeneratedFile
+	for _, f := range modelResp.GetFile() {
+		models = append(models, &generator.GeneratedFile{Path: f.GetName(), Content: f.GetContent()})
+	}
+	hzPlugin.AddModelFiles(models)
+	if err := hzPlugin.Run(); err != nil {
+		return err
+	}
+	// dry_run模式下模型代码与HTTP代码一样只出现在差异报告中
+	if !hzPlugin.DryRun() {
+		if err := writeResponse(opts, modelResp); err != nil {
+			return err
+		}
+	}
+	return writeResponse(opts, gen.Response())
+}
+
+// compile 用protocompile编译idl及其依赖，按依赖顺序返回文件描述符
+func compile(opts *options) ([]*descriptorpb.FileDescriptorProto, error) {
+	importPaths := opts.protoPaths
+	names := make([]string, len(opts.idls))
+	for i, idl := range opts.idls {
+		name, err := relativeIDL(idl, importPaths)
+		if err != nil {
+			return nil, err
+		}
+		names[i] = name
+	}
+	if len(importPaths) == 0 {
+		// 未指定搜索路径时，使用各idl所在目录
+		seen := map[string]bool{}
+		for i, idl := range opts.idls {
+			dir := filepath.Dir(idl)
+			if !seen[dir] {
+				seen[dir] = true
+				importPaths = append(importPaths, dir)
+			}
+			names[i] = filepath.Base(idl)
+		}
+	}
+	opts.idls = names
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.CompositeResolver{
+			protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: importPaths}),
+			// hz/hz.proto 与 errors/errors.proto 等插件自带的注解无需额外指定搜索路径
+			protocompile.ResolverFunc(func(path string) (protocompile.SearchResult, error) {
+				fd, err := protoregistry.GlobalFiles.FindFileByPath(path)
+				if err != nil {
+					return protocompile.SearchResult{}, err
+				}
+				return protocompile.SearchResult{Desc: fd}, nil
+			}),
+		},
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	result, err := compiler.Compile(context.Background(), names...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*descriptorpb.FileDescriptorProto
+	seen := map[string]bool{}
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		imports := fd.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			add(imports.Get(i).FileDescriptor)
+		}
+		files = append(files, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, fd := range result {
+		add(fd)
+	}
+
+	// 选项中消息类型的扩展（如 hz.auth）在编译结果中是 dynamicpb 消息，
+	// 经过序列化后按已注册的扩展类型重新解析，与 protoc 传给插件的请求一致
+	for i, fd := range files {
+		data, err := proto.Marshal(fd)
+		if err != nil {
+			return nil, err
+		}
+		files[i] = &descriptorpb.FileDescriptorProto{}
+		if err := proto.Unmarshal(data, files[i]); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// relativeIDL idl相对于所在搜索路径的名称，即proto文件中import使用的路径
+func relativeIDL(idl string, importPaths []string) (string, error) {
+	if len(importPaths) == 0 {
+		return idl, nil
+	}
+	abs, err := filepath.Abs(idl)
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range importPaths {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return "", err
+		}
+		if rel, err := filepath.Rel(absDir, abs); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel), nil
+		}
+	}
+	return "", fmt.Errorf("%s is not in any --proto_path", idl)
+}
+
+func (opts *options) isIDL(name string) bool {
+	for _, idl := range opts.idls {
+		if idl == name {
+			return true
+		}
+	}
+	return false
+}
+
+// moduleFromGoMod 读取dir下go.mod中的module
+func moduleFromGoMod(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module"); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// writeResponse 将插件响应中的文件写入out_dir
+func writeResponse(opts *options, resp *pluginpb.CodeGeneratorResponse) error {
+	if resp.Error != nil {
+		return errors.New(resp.GetError())
+	}
+	for _, f := range resp.GetFile() {
+		path := filepath.Join(opts.outDir, filepath.FromSlash(f.GetName()))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(f.GetContent()), 0o644); err != nil {
+			return err
+		}
+		if opts.verbose {
+			fmt.Fprintln(os.Stderr, "write", path)
+		}
+	}
+	return nil
+}
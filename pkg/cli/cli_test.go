@@ -0,0 +1,228 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ca-x/protoc-gen-go-hz/pkg/version"
+)
+
+const demoProto = `syntax = "proto3";
+
+package demo;
+
+option go_package = "example.com/demo/biz/model/demo";
+
+import "hz/hz.proto";
+
+service Books {
+  rpc GetBook(GetBookRequest) returns (Book) {
+    option (hz.auth) = {scheme: "bearer", scopes: ["books.read"]};
+  }
+}
+
+message GetBookRequest { string name = 1; }
+message Book { string name = 1; }
+`
+
+// writeProto 在临时目录中写入proto文件，返回其路径
+func writeProto(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// listFiles out下的全部文件，以斜杠分隔的相对路径排序返回
+func listFiles(t *testing.T, out string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.Walk(out, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(out, path)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestMainExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	idl := writeProto(t, dir, "demo.proto", demoProto)
+	broken := writeProto(t, dir, "broken.proto", "syntax = \"proto3\";\nmessage {\n")
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"no arguments", nil, 2},
+		{"help", []string{"help"}, 0},
+		{"version", []string{"version"}, 0},
+		{"unknown command", []string{"generate"}, 1},
+		{"unknown flag", []string{"new", "--idl", idl, "--no_such_flag"}, 1},
+		{"flag help", []string{"new", "-h"}, 2},
+		{"no idl", []string{"new", "--out_dir", t.TempDir()}, 1},
+		{"missing idl", []string{"new", "--idl", filepath.Join(dir, "missing.proto"), "--out_dir", t.TempDir()}, 1},
+		{"syntax error", []string{"new", "--idl", broken, "--out_dir", t.TempDir()}, 1},
+		{"invalid option", []string{"new", "--idl", idl, "--out_dir", t.TempDir(), "--opt", "strict=true", "--opt", "envelope=wrapped"}, 1},
+		{"new", []string{"new", "--idl", idl, "--out_dir", t.TempDir()}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Main(tt.args); got != tt.want {
+				t.Errorf("Main(%q) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunVersion(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := Run([]string{"version"}, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(stdout.String()); got != version.Version {
+		t.Errorf("version = %q, want %q", got, version.Version)
+	}
+}
+
+func TestNewAndUpdate(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	idl := writeProto(t, dir, "demo.proto", demoProto)
+
+	if code := Main([]string{"new", "--idl", idl, "--out_dir", out, "--client_dir", "biz/client"}); code != 0 {
+		t.Fatalf("new exited with %d", code)
+	}
+	want := []string{
+		".gitignore",
+		".hz",
+		"biz/auth/auth.go",
+		"biz/client/Books_client.go",
+		"biz/client/client.go",
+		"biz/codec/codec.go",
+		"biz/codec/query.go",
+		"biz/errors/errors.go",
+		"biz/handler/Books_service.go",
+		"biz/handler/GetBook.go",
+		"biz/handler/ping.go",
+		"biz/model/demo/demo.pb.go",
+		"biz/router/register.go",
+		"biz/router/router.go",
+		"biz/router/routes.go",
+		"go.mod",
+		"main.go",
+		"router.go",
+		"router_gen.go",
+	}
+	if got := listFiles(t, out); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("new wrote\n\t%s\nwant\n\t%s", strings.Join(got, "\n\t"), strings.Join(want, "\n\t"))
+	}
+	if gomod := readFile(t, filepath.Join(out, "go.mod")); !strings.HasPrefix(gomod, "module example.com/demo\n") {
+		t.Errorf("go.mod does not use the module from go_package:\n%s", gomod)
+	}
+	if manifest := readFile(t, filepath.Join(out, ".hz")); !strings.Contains(manifest, "client_dir: biz/client") {
+		t.Errorf(".hz does not record client_dir:\n%s", manifest)
+	}
+
+	// (hz.auth) 是消息类型的扩展，编译结果需按已注册的扩展类型重新解析才能被插件识别
+	router := readFile(t, filepath.Join(out, "biz", "router", "router.go"))
+	if !strings.Contains(router, "auth.Require(auth.BooksGetBook), handler.GetBook") {
+		t.Errorf("router.go does not guard GetBook with (hz.auth):\n%s", router)
+	}
+	if authGo := readFile(t, filepath.Join(out, "biz", "auth", "auth.go")); !strings.Contains(authGo, `"books.read"`) {
+		t.Errorf("auth.go lacks the scopes of (hz.auth):\n%s", authGo)
+	}
+
+	// update 保留用户修改的布局文件，为新方法生成handler，并复用清单中的client_dir
+	mainGo := filepath.Join(out, "main.go")
+	edited := readFile(t, mainGo) + "\n// edited\n"
+	if err := os.WriteFile(mainGo, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeProto(t, dir, "demo.proto", strings.Replace(demoProto, "service Books {",
+		"service Books {\n  rpc ListBooks(GetBookRequest) returns (Book);", 1))
+	if code := Main([]string{"update", "--idl", idl, "--out_dir", out}); code != 0 {
+		t.Fatalf("update exited with %d", code)
+	}
+	if got := readFile(t, mainGo); got != edited {
+		t.Error("update overwrote main.go")
+	}
+	for _, name := range []string{"biz/handler/ListBooks.go", "biz/client/Books_client.go"} {
+		if _, err := os.Stat(filepath.Join(out, filepath.FromSlash(name))); err != nil {
+			t.Errorf("update did not write %s: %v", name, err)
+		}
+	}
+	if client := readFile(t, filepath.Join(out, "biz", "client", "Books_client.go")); !strings.Contains(client, "ListBooks") {
+		t.Error("update did not regenerate the client with the recorded client_dir")
+	}
+}
+
+// TestProtoPath 以 --proto_path 指定搜索路径时，idl按相对于搜索路径的名称编译，可以导入同一路径下的文件
+func TestProtoPath(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "common"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeProto(t, dir, "common/page.proto", `syntax = "proto3";
+package common;
+option go_package = "example.com/demo/biz/model/common";
+message Page { int32 size = 1; }
+`)
+	idl := writeProto(t, dir, "demo.proto", strings.Replace(demoProto, `import "hz/hz.proto";`,
+		"import \"hz/hz.proto\";\nimport \"common/page.proto\";", 1)+"message List { common.Page page = 1; }\n")
+
+	out := filepath.Join(dir, "out")
+	if code := Main([]string{"new", "-I", dir, "--idl", idl, "--out_dir", out}); code != 0 {
+		t.Fatalf("new exited with %d", code)
+	}
+	// 依赖的proto只用于编译，不生成模型
+	if _, err := os.Stat(filepath.Join(out, "biz", "model", "common", "page.pb.go")); err == nil {
+		t.Error("models were generated for an imported file")
+	}
+	if _, err := os.Stat(filepath.Join(out, "biz", "model", "demo", "demo.pb.go")); err != nil {
+		t.Error(err)
+	}
+
+	if code := Main([]string{"new", "-I", filepath.Join(dir, "common"), "--idl", idl, "--out_dir", t.TempDir()}); code != 1 {
+		t.Errorf("an idl outside --proto_path exited with %d, want 1", code)
+	}
+}
@@ -21,6 +21,7 @@ package config
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/cloudwego/hertz/cmd/hz/meta"
@@ -41,8 +42,8 @@ type Argument struct {
 	ClientDir  string // client目录
 	BaseDomain string // 请求域名
 
-	// Go模块相关 - 自动从proto的go_package提取
-	Gomod       string // Go模块名（从proto自动提取）
+	// Go模块相关 - 未指定时从proto的go_package提取
+	Gomod       string // Go模块名（参数go_module，未指定时从proto自动提取）
 	ServiceName string // 服务名
 	Use         string // 使用第三方模型包
 	NeedGoMod   bool   // 是否需要生成go.mod
@@ -65,13 +66,30 @@ type Argument struct {
 	SortRouter           bool     // 排序路由代码
 	ForceUpdateClient    bool     // 强制更新客户端代码
 	OnlyModel            bool     // 仅生成模型代码
+	ClientCodec          string   // 生成客户端的默认编码: json, protobuf, prototext
+	ProtoText            bool     // 内容协商支持protobuf文本格式
+	Envelope             string   // JSON响应包装格式: none, code_msg_data，可被服务选项 (hz.envelope) 覆盖
+	WebSocket            bool     // 客户端流式与双向流式方法使用WebSocket传输
+	HandlerTest          bool     // 为每个服务生成handler测试脚手架，已存在时不覆盖
+	ClientMock           bool     // 在client目录的mock包中为每个服务生成mock实现
+	OTel                 bool     // 为路由生成OpenTelemetry追踪与RED指标，客户端传播追踪上下文
+	RoutesJSON           bool     // 在router目录输出routes.json路由表
+	Strict               bool     // 严格模式：未知参数或非法取值直接报错
+	ConfigFile           string   // 配置文件路径，文件中的参数被命令行参数覆盖
+	VersionCheck         string   // 版本检查模式: warn（默认）, error, off
+	Verify               bool     // 生成后对生成的包做类型检查
+	DryRun               bool     // 不写入文件，输出与out_dir中现有文件的差异
+	DryRunReport         string   // 差异报告文件路径，为空时输出到stderr
+	Warnings             []string // 非严格模式下记录的参数问题，verbose模式下输出
 
 	// 命令类型 - 显式指定，避免自动检测的不确定性
-	CmdType string // 命令类型: "new", "update", "model", "client"
+	CmdType string // 命令类型: "new", "update"
 
 	// 自定义选项
 	CustomizeLayout  string // 自定义布局模板路径
 	CustomizePackage string // 自定义包模板路径
+
+	explicit map[string]string // 命令行与配置文件显式给出的参数，用于写入和应用项目清单
 }
 
 // Unpack 解析参数列表，将 protoc 传递的 key=value 格式参数解析到 Argument 结构体。
@@ -81,6 +99,8 @@ type Argument struct {
 //   - 布尔值: verbose=true, need_go_mod=1
 //   - 字符串: out_dir=., service=demo
 //   - 列表: exclude_file=a.proto,b.proto
+//   - 严格模式: strict=true 时未知参数与非法取值直接报错
+//   - 配置文件: config=.hz-proto.yaml 从文件加载参数，命令行参数优先
 //
 // 该方法会自动初始化默认值并验证参数格式。
 func (arg *Argument) Unpack(params []string) error {
@@ -113,19 +133,79 @@ func (arg *Argument) Unpack(params []string) error {
 		arg.ServiceName = meta.DefaultServiceName
 	}
 
+	// 先确定严格模式与配置文件，使其对出现在之前的参数同样生效
+	strictSet := false
+	for _, param := range params {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "strict":
+			if err := arg.setStrict(value); err != nil {
+				return err
+			}
+			strictSet = true
+		case "config":
+			arg.ConfigFile = strings.TrimSpace(value)
+		}
+	}
+
+	// 加载配置文件，命令行参数优先于文件中的同名参数
+	var fileParams []string
+	if arg.ConfigFile != "" {
+		var err error
+		if fileParams, err = LoadConfigFile(arg.ConfigFile); err != nil {
+			return err
+		}
+		for _, param := range fileParams {
+			if key, value, _ := strings.Cut(param, "="); key == "strict" && !strictSet {
+				if err := arg.setStrict(value); err != nil {
+					return fmt.Errorf("config file %s: %v", arg.ConfigFile, err)
+				}
+			}
+		}
+	}
+
 	// 解析参数
+	cliKeys := make(map[string]bool)
 	for _, param := range params {
 		if err := arg.parseParam(param); err != nil {
 			return err
 		}
+		if param != "" {
+			arg.recordParam(param)
+		}
+		key, _, _ := strings.Cut(param, "=")
+		cliKeys[strings.TrimSpace(key)] = true
+	}
+	for _, param := range fileParams {
+		key, _, _ := strings.Cut(param, "=")
+		if cliKeys[key] {
+			continue
+		}
+		if err := arg.parseParam(param); err != nil {
+			return fmt.Errorf("config file %s: %v", arg.ConfigFile, err)
+		}
+		arg.recordParam(param)
 	}
 
 	return nil
 }
 
+// setStrict 解析 strict 参数，其取值始终严格校验
+func (arg *Argument) setStrict(value string) error {
+	strict, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return fmt.Errorf("invalid value %q for strict, expected true or false", value)
+	}
+	arg.Strict = strict
+	return nil
+}
+
 // parseParam 解析单个参数，将 key=value 格式的参数解析并赋值到对应字段。
 // 支持的参数类型包括布尔值、字符串、列表等。
-// 对于未知参数，会静默忽略以保持向后兼容性。
+// 未知参数与非法取值在严格模式下返回错误，否则记录到 Warnings 中。
 func (arg *Argument) parseParam(param string) error {
 	if param == "" {
 		return nil
@@ -140,9 +220,12 @@ func (arg *Argument) parseParam(param string) error {
 	key := strings.TrimSpace(parts[0])
 	value := strings.TrimSpace(parts[1])
 
+	var err error
 	switch key {
+	case "strict", "config":
+		// 已在 Unpack 中预先解析
 	case "verbose":
-		arg.Verbose = value == "true" || value == "1"
+		arg.Verbose, err = arg.parseBool(key, value)
 	case "out_dir":
 		arg.OutDir = value
 	case "handler_dir":
@@ -153,6 +236,8 @@ func (arg *Argument) parseParam(param string) error {
 		arg.RouterDir = value
 	case "client_dir":
 		arg.ClientDir = value
+	case "go_module":
+		arg.Gomod = value
 	case "base_domain":
 		arg.BaseDomain = value
 	case "service":
@@ -160,27 +245,51 @@ func (arg *Argument) parseParam(param string) error {
 	case "use":
 		arg.Use = value
 	case "need_go_mod":
-		arg.NeedGoMod = value == "true" || value == "1"
+		arg.NeedGoMod, err = arg.parseBool(key, value)
 	case "model":
-		arg.OnlyModel = value == "true" || value == "1"
+		arg.OnlyModel, err = arg.parseBool(key, value)
 	case "json_enumstr":
-		arg.JSONEnumStr = value == "true" || value == "1"
+		arg.JSONEnumStr, err = arg.parseBool(key, value)
 	case "query_enumint":
-		arg.QueryEnumAsInt = value == "true" || value == "1"
+		arg.QueryEnumAsInt, err = arg.parseBool(key, value)
 	case "unset_omitempty":
-		arg.UnsetOmitempty = value == "true" || value == "1"
+		arg.UnsetOmitempty, err = arg.parseBool(key, value)
 	case "pb_camel_json_tag":
-		arg.ProtobufCamelJSONTag = value == "true" || value == "1"
+		arg.ProtobufCamelJSONTag, err = arg.parseBool(key, value)
 	case "snake_tag":
-		arg.SnakeName = value == "true" || value == "1"
+		arg.SnakeName, err = arg.parseBool(key, value)
 	case "no_recurse":
-		arg.NoRecurse = value == "true" || value == "1"
+		arg.NoRecurse, err = arg.parseBool(key, value)
 	case "handler_by_method":
-		arg.HandlerByMethod = value == "true" || value == "1"
+		arg.HandlerByMethod, err = arg.parseBool(key, value)
 	case "sort_router":
-		arg.SortRouter = value == "true" || value == "1"
+		arg.SortRouter, err = arg.parseBool(key, value)
 	case "force_client":
-		arg.ForceUpdateClient = value == "true" || value == "1"
+		arg.ForceUpdateClient, err = arg.parseBool(key, value)
+	case "client_codec":
+		arg.ClientCodec, err = arg.parseEnum(key, value)
+	case "proto_text":
+		arg.ProtoText, err = arg.parseBool(key, value)
+	case "envelope":
+		arg.Envelope, err = arg.parseEnum(key, value)
+	case "version_check":
+		arg.VersionCheck, err = arg.parseEnum(key, value)
+	case "websocket":
+		arg.WebSocket, err = arg.parseBool(key, value)
+	case "client_mock":
+		arg.ClientMock, err = arg.parseBool(key, value)
+	case "routes_json":
+		arg.RoutesJSON, err = arg.parseBool(key, value)
+	case "otel":
+		arg.OTel, err = arg.parseBool(key, value)
+	case "handler_test":
+		arg.HandlerTest, err = arg.parseBool(key, value)
+	case "verify":
+		arg.Verify, err = arg.parseBool(key, value)
+	case "dry_run":
+		arg.DryRun, err = arg.parseBool(key, value)
+	case "dry_run_report":
+		arg.DryRunReport = value
 	case "exclude_file":
 		arg.Excludes = append(arg.Excludes, strings.Split(value, ",")...)
 	case "rm_tag":
@@ -193,31 +302,121 @@ func (arg *Argument) parseParam(param string) error {
 		arg.TrimGoPackage = value
 	case "cmd_type":
 		// 显式指定命令类型，避免自动检测带来的不确定性
-		arg.CmdType = value
+		arg.CmdType, err = arg.parseEnum(key, value)
 	default:
 		if strings.HasPrefix(key, "option_package:") {
 			// 解析option_package参数
 			pkgParam := strings.TrimPrefix(key, "option_package:")
-			if err := arg.parseOptionPackage(pkgParam, value); err != nil {
-				return err
-			}
-		} else {
-			// 未知的参数，记录警告但不返回错误（符合protoc插件的宽容原则）
-			// 这允许插件忽略不认识的参数，便于未来扩展
-			// 用户可以通过verbose模式查看这些警告
-			if strings.HasPrefix(key, "paths") || strings.HasPrefix(key, "import") {
-				// 这些可能是其他插件的参数，静默忽略
-				return nil
-			}
-			// 对于其他未知参数，如果在严格模式下可以返回错误
-			// 但为了兼容性，我们只记录日志
+			return arg.parseOptionPackage(pkgParam, value)
+		}
+		if strings.HasPrefix(key, "paths") || strings.HasPrefix(key, "import") {
+			// 这些可能是其他插件的参数，静默忽略
 			return nil
 		}
+		// 未知参数：严格模式下报错，否则记录警告（符合protoc插件的宽容原则）
+		msg := fmt.Sprintf("unknown parameter %q", key)
+		if s := suggestParam(key); s != "" {
+			msg += fmt.Sprintf(", did you mean %q?", s)
+		}
+		return arg.problem(msg)
 	}
 
+	return err
+}
+
+// knownParams 支持的参数名，用于未知参数的拼写建议，新增参数时需同步维护
+var knownParams = []string{
+	"verbose", "out_dir", "handler_dir", "model_dir", "router_dir", "client_dir", "go_module",
+	"base_domain", "service", "use", "need_go_mod", "model", "json_enumstr",
+	"query_enumint", "unset_omitempty", "pb_camel_json_tag", "snake_tag",
+	"no_recurse", "handler_by_method", "sort_router", "force_client",
+	"client_codec", "proto_text", "envelope", "websocket", "exclude_file",
+	"rm_tag", "customize_layout", "customize_package", "trim_gopackage",
+	"cmd_type", "strict", "config", "version_check", "verify", "dry_run",
+	"dry_run_report", "handler_test", "client_mock", "otel", "routes_json",
+}
+
+// enumParams 取值受限的参数及其合法取值
+var enumParams = map[string][]string{
+	"cmd_type":      {meta.CmdNew, meta.CmdUpdate},
+	"client_codec":  {"json", "protobuf", "prototext"},
+	"envelope":      {"none", "code_msg_data"},
+	"version_check": {"warn", "error", "off"},
+}
+
+// problem 处理参数问题：严格模式下返回错误，否则记录为警告
+func (arg *Argument) problem(msg string) error {
+	if arg.Strict {
+		return fmt.Errorf("%s", msg)
+	}
+	arg.Warnings = append(arg.Warnings, msg)
 	return nil
 }
 
+// parseBool 解析布尔参数，接受 strconv.ParseBool 支持的取值（true/false/1/0等）。
+// 非严格模式下非法取值按 false 处理并记录警告。
+func (arg *Argument) parseBool(key, value string) (bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, arg.problem(fmt.Sprintf("invalid value %q for %s, expected true or false", value, key))
+	}
+	return b, nil
+}
+
+// fatalEnumParams 非法取值无论是否严格模式都报错的参数：生成阶段不再校验它们，
+// 非法取值会被当作默认行为，如 cmd_type=updte 按新建项目生成
+var fatalEnumParams = map[string]bool{
+	"cmd_type":      true,
+	"version_check": true,
+}
+
+// parseEnum 解析取值受限的参数。非严格模式下非法取值原样保留并记录警告，
+// 由生成阶段的校验决定是否报错；fatalEnumParams 中的参数直接报错。
+func (arg *Argument) parseEnum(key, value string) (string, error) {
+	for _, v := range enumParams[key] {
+		if value == v {
+			return value, nil
+		}
+	}
+	msg := fmt.Sprintf("invalid value %q for %s, expected one of %s", value, key, strings.Join(enumParams[key], ", "))
+	if fatalEnumParams[key] {
+		return "", fmt.Errorf("%s", msg)
+	}
+	return value, arg.problem(msg)
+}
+
+// suggestParam 返回与未知参数名编辑距离最近的已知参数名，距离过大时返回空
+func suggestParam(key string) string {
+	best, bestDist := "", len(key)/2+1
+	for _, p := range knownParams {
+		if d := editDistance(key, p); d < bestDist {
+			best, bestDist = p, d
+		}
+	}
+	return best
+}
+
+// editDistance 计算两个字符串的编辑距离（Levenshtein距离）
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
 // parseOptionPackage 解析 option_package 参数，用于指定 proto 包到 Go 包的映射。
 // 参数格式: option_package:proto_package=go_package
 //
@@ -242,6 +441,20 @@ func (arg *Argument) GetGoPackage() (string, error) {
 	return arg.Gomod, nil
 }
 
+// ModuleFromGoPackage 从go_package推导Go模块根路径：
+// 包含 /biz/ 时取其之前的部分，否则去掉最后一个路径段
+// 例如 "github.com/example/project/biz/model" -> "github.com/example/project"
+func ModuleFromGoPackage(goPackage string) string {
+	goPackage, _, _ = strings.Cut(goPackage, ";")
+	if idx := strings.Index(goPackage, "/biz/"); idx != -1 {
+		return goPackage[:idx]
+	}
+	if idx := strings.LastIndex(goPackage, "/"); idx != -1 {
+		return goPackage[:idx]
+	}
+	return goPackage
+}
+
 // GetModelDir 获取模型代码生成目录，如果未指定则使用默认值。
 // 默认目录为 "biz/model"。
 func (arg *Argument) GetModelDir() (string, error) {
@@ -0,0 +1,205 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnpack(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   []string
+		check    func(t *testing.T, arg *Argument)
+		err      string   // 期望错误包含的内容，为空时期望成功
+		warnings []string // 期望记录的警告
+	}{
+		{
+			name:   "defaults",
+			params: nil,
+			check: func(t *testing.T, arg *Argument) {
+				if arg.OutDir != "." || arg.HandlerDir != "biz/handler" || arg.ModelDir != "biz/model" || arg.RouterDir != "biz/router" {
+					t.Errorf("dirs = %q %q %q %q", arg.OutDir, arg.HandlerDir, arg.ModelDir, arg.RouterDir)
+				}
+			},
+		},
+		{
+			name:   "values",
+			params: []string{"verbose=1", " handler_dir = api/handler ", "client_codec=protobuf", "exclude_file=a.proto", "exclude_file=b.proto"},
+			check: func(t *testing.T, arg *Argument) {
+				if !arg.Verbose || arg.HandlerDir != "api/handler" || arg.ClientCodec != "protobuf" {
+					t.Errorf("got verbose=%v handler_dir=%q client_codec=%q", arg.Verbose, arg.HandlerDir, arg.ClientCodec)
+				}
+				if want := []string{"a.proto", "b.proto"}; !reflect.DeepEqual(arg.Excludes, want) {
+					t.Errorf("Excludes = %q, want %q", arg.Excludes, want)
+				}
+			},
+		},
+		{
+			name:     "unknown parameter warns",
+			params:   []string{"hanlder_dir=x"},
+			warnings: []string{`unknown parameter "hanlder_dir", did you mean "handler_dir"?`},
+		},
+		{
+			name:     "unknown parameter without suggestion",
+			params:   []string{"frobnicate=1"},
+			warnings: []string{`unknown parameter "frobnicate"`},
+		},
+		{
+			name:   "unknown parameter strict",
+			params: []string{"strict=true", "verbos=true"},
+			err:    `unknown parameter "verbos", did you mean "verbose"?`,
+		},
+		{
+			name:   "strict applies to earlier parameters",
+			params: []string{"verbos=true", "strict=true"},
+			err:    `unknown parameter "verbos"`,
+		},
+		{
+			name:     "invalid bool warns",
+			params:   []string{"otel=yes"},
+			warnings: []string{`invalid value "yes" for otel, expected true or false`},
+			check: func(t *testing.T, arg *Argument) {
+				if arg.OTel {
+					t.Error("invalid bool should be false")
+				}
+			},
+		},
+		{
+			name:   "invalid bool strict",
+			params: []string{"strict=1", "websocket=on"},
+			err:    `invalid value "on" for websocket, expected true or false`,
+		},
+		{
+			name:     "invalid enum warns",
+			params:   []string{"envelope=wrapped"},
+			warnings: []string{`invalid value "wrapped" for envelope, expected one of none, code_msg_data`},
+			check: func(t *testing.T, arg *Argument) {
+				if arg.Envelope != "wrapped" {
+					t.Errorf("Envelope = %q, invalid enum values are kept for later validation", arg.Envelope)
+				}
+			},
+		},
+		{
+			name:   "invalid enum strict",
+			params: []string{"strict=true", "version_check=never"},
+			err:    `invalid value "never" for version_check, expected one of warn, error, off`,
+		},
+		{
+			name:   "invalid cmd_type fails without strict",
+			params: []string{"cmd_type=updte"},
+			err:    `invalid value "updte" for cmd_type, expected one of new, update`,
+		},
+		{
+			name:   "invalid version_check fails without strict",
+			params: []string{"version_check=never"},
+			err:    `invalid value "never" for version_check, expected one of warn, error, off`,
+		},
+		{
+			name:   "invalid strict value",
+			params: []string{"strict=maybe"},
+			err:    `invalid value "maybe" for strict`,
+		},
+		{
+			name:   "missing equals",
+			params: []string{"verbose"},
+			err:    "invalid parameter format: verbose",
+		},
+		{
+			name:   "warnings accumulate in order",
+			params: []string{"dry_rn=true", "need_go_mod=2", "client_codec=xml"},
+			warnings: []string{
+				`unknown parameter "dry_rn", did you mean "dry_run"?`,
+				`invalid value "2" for need_go_mod, expected true or false`,
+				`invalid value "xml" for client_codec, expected one of json, protobuf, prototext`,
+			},
+		},
+		{
+			name:   "other plugin parameters are ignored",
+			params: []string{"paths=source_relative", "import_prefix=x"},
+		},
+		{
+			name:   "option_package",
+			params: []string{"option_package:google/protobuf=example.com/ptypes"},
+			check: func(t *testing.T, arg *Argument) {
+				if got := arg.OptPkgMap["google/protobuf"]; got != "example.com/ptypes" {
+					t.Errorf("OptPkgMap = %v", arg.OptPkgMap)
+				}
+			},
+		},
+		{
+			name:   "empty option_package",
+			params: []string{"option_package:google/protobuf="},
+			err:    "option_package value cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arg := &Argument{}
+			err := arg.Unpack(tt.params)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Unpack(%q) error = %v, want %q", tt.params, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unpack(%q): %v", tt.params, err)
+			}
+			if !reflect.DeepEqual(arg.Warnings, tt.warnings) {
+				t.Errorf("Warnings = %q, want %q", arg.Warnings, tt.warnings)
+			}
+			if tt.check != nil {
+				tt.check(t, arg)
+			}
+		})
+	}
+}
+
+func TestSuggestParam(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"verbos", "verbose"},
+		{"handlerdir", "handler_dir"},
+		{"client_codex", "client_codec"},
+		{"otle", "otel"},
+		{"routes_jsn", "routes_json"},
+		{"frobnicate", ""},
+		{"xyz", ""},
+	}
+	for _, tt := range tests {
+		if got := suggestParam(tt.key); got != tt.want {
+			t.Errorf("suggestParam(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+// TestKnownParams 每个已知参数都由 parseParam 处理，不会被当作未知参数
+func TestKnownParams(t *testing.T) {
+	for _, key := range knownParams {
+		arg := &Argument{Strict: true}
+		err := arg.parseParam(key + "=x")
+		if err != nil && strings.Contains(err.Error(), "unknown parameter") {
+			t.Errorf("%s is listed in knownParams but not handled by parseParam", key)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// listParams 可以在配置文件中以列表形式给出的参数
+var listParams = map[string]bool{
+	"exclude_file": true,
+	"rm_tag":       true,
+}
+
+// LoadConfigFile 加载插件配置文件（YAML 或 JSON，按扩展名 .json 区分），
+// 并将其转换为与命令行相同的 key=value 参数列表，按参数名排序。
+// 配置文件的键与命令行参数名一致，结构见 schema.json，例如:
+//
+//	handler_dir: biz/handler
+//	exclude_file: [a.proto, b.proto]
+//	option_package:
+//	  google/protobuf: github.com/golang/protobuf/ptypes
+func LoadConfigFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file %s failed: %v", path, err)
+	}
+
+	values := map[string]interface{}{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &values)
+	} else {
+		err = yaml.Unmarshal(data, &values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse config file %s failed: %v", path, err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var params []string
+	for _, key := range keys {
+		kvs, err := configParams(key, values[key])
+		if err != nil {
+			return nil, fmt.Errorf("config file %s: %v", path, err)
+		}
+		params = append(params, kvs...)
+	}
+	return params, nil
+}
+
+// configParams 将配置文件中的单个键值转换为命令行参数
+func configParams(key string, value interface{}) ([]string, error) {
+	switch key {
+	case "config":
+		return nil, fmt.Errorf("config cannot be set in a config file")
+	case "option_package":
+		m, ok := toStringMap(value)
+		if !ok {
+			return nil, fmt.Errorf("option_package must map proto packages to Go packages")
+		}
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		params := make([]string, 0, len(m))
+		for _, name := range names {
+			params = append(params, "option_package:"+name+"="+m[name])
+		}
+		return params, nil
+	}
+
+	if list, ok := value.([]interface{}); ok {
+		if !listParams[key] {
+			return nil, fmt.Errorf("%s does not accept a list", key)
+		}
+		params := make([]string, 0, len(list))
+		for _, item := range list {
+			s, ok := scalarString(item)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of strings", key)
+			}
+			params = append(params, key+"="+s)
+		}
+		return params, nil
+	}
+
+	s, ok := scalarString(value)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a string, number or boolean", key)
+	}
+	return []string{key + "=" + s}, nil
+}
+
+// scalarString 将标量值格式化为命令行参数值
+func scalarString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), true
+	case nil:
+		return "", true
+	}
+	return "", false
+}
+
+// toStringMap 将 YAML/JSON 解析出的映射转换为字符串映射
+func toStringMap(v interface{}) (map[string]string, bool) {
+	m := map[string]string{}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			s, ok := scalarString(val)
+			if !ok {
+				return nil, false
+			}
+			m[k] = s
+		}
+	case map[interface{}]interface{}:
+		for k, val := range v {
+			s, ok := scalarString(val)
+			if !ok {
+				return nil, false
+			}
+			m[fmt.Sprint(k)] = s
+		}
+	default:
+		return nil, false
+	}
+	return m, true
+}
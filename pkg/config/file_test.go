@@ -0,0 +1,187 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []string
+		err     string
+	}{
+		{
+			name: "yaml",
+			file: ".hz-proto.yaml",
+			content: `handler_dir: api/handler
+otel: true
+exclude_file: [a.proto, b.proto]
+option_package:
+  google/protobuf: example.com/ptypes
+`,
+			want: []string{
+				"exclude_file=a.proto",
+				"exclude_file=b.proto",
+				"handler_dir=api/handler",
+				"option_package:google/protobuf=example.com/ptypes",
+				"otel=true",
+			},
+		},
+		{
+			name:    "json",
+			file:    "hz.json",
+			content: `{"client_dir": "biz/client", "verbose": false, "rm_tag": ["json", "form"]}`,
+			want:    []string{"client_dir=biz/client", "rm_tag=json", "rm_tag=form", "verbose=false"},
+		},
+		{
+			name:    "comma separated value",
+			file:    "hz.yaml",
+			content: "exclude_file: a.proto,b.proto\nrm_tag: [\"json,form\"]\n",
+			want:    []string{"exclude_file=a.proto,b.proto", "rm_tag=json,form"},
+		},
+		{
+			name:    "config in config file",
+			file:    "hz.yaml",
+			content: "config: other.yaml\n",
+			err:     "config cannot be set in a config file",
+		},
+		{
+			name:    "list for scalar parameter",
+			file:    "hz.yaml",
+			content: "handler_dir: [a, b]\n",
+			err:     "handler_dir does not accept a list",
+		},
+		{
+			name:    "invalid option_package",
+			file:    "hz.json",
+			content: `{"option_package": "x"}`,
+			err:     "option_package must map proto packages to Go packages",
+		},
+		{
+			name:    "malformed json",
+			file:    "hz.json",
+			content: `{"verbose": }`,
+			err:     "parse config file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadConfigFile(path)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("LoadConfigFile error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadConfigFile = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileMissing(t *testing.T) {
+	_, err := LoadConfigFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "read config file") {
+		t.Fatalf("error = %v", err)
+	}
+}
+
+// TestUnpackConfigFile 配置文件中的参数被命令行同名参数覆盖
+func TestUnpackConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".hz-proto.yaml")
+	content := `handler_dir: api/handler
+router_dir: api/router
+exclude_file: a.proto,b.proto
+rm_tag: [json, "form,query"]
+client_codec: protobuf
+strict: true
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	arg := &Argument{}
+	if err := arg.Unpack([]string{"handler_dir=cli/handler", "config=" + path, "client_codec=json"}); err != nil {
+		t.Fatal(err)
+	}
+	if arg.HandlerDir != "cli/handler" {
+		t.Errorf("HandlerDir = %q, the command line should override the file", arg.HandlerDir)
+	}
+	if arg.RouterDir != "api/router" {
+		t.Errorf("RouterDir = %q, want the value from the file", arg.RouterDir)
+	}
+	if arg.ClientCodec != "json" {
+		t.Errorf("ClientCodec = %q, want json", arg.ClientCodec)
+	}
+	if !arg.Strict {
+		t.Error("strict from the config file was not applied")
+	}
+	if want := []string{"a.proto", "b.proto"}; !reflect.DeepEqual(arg.Excludes, want) {
+		t.Errorf("Excludes = %q, want %q", arg.Excludes, want)
+	}
+	if want := []string{"json", "form", "query"}; !reflect.DeepEqual(arg.RmTags, want) {
+		t.Errorf("RmTags = %q, want %q", arg.RmTags, want)
+	}
+
+	// 命令行给出列表参数时整体替换文件中的取值
+	arg = &Argument{}
+	if err := arg.Unpack([]string{"config=" + path, "exclude_file=c.proto"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"c.proto"}; !reflect.DeepEqual(arg.Excludes, want) {
+		t.Errorf("Excludes = %q, want %q", arg.Excludes, want)
+	}
+}
+
+// TestUnpackConfigFileStrict 文件中的 strict 可被命令行关闭，文件中的非法取值带上文件名
+func TestUnpackConfigFileStrict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hz.json")
+	if err := os.WriteFile(path, []byte(`{"strict": true, "envelope": "wrapped"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	arg := &Argument{}
+	err := arg.Unpack([]string{"config=" + path})
+	if err == nil || !strings.Contains(err.Error(), "config file "+path) || !strings.Contains(err.Error(), `invalid value "wrapped" for envelope`) {
+		t.Fatalf("error = %v", err)
+	}
+
+	arg = &Argument{}
+	if err := arg.Unpack([]string{"config=" + path, "strict=false"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(arg.Warnings) != 1 {
+		t.Errorf("Warnings = %q, want one warning for envelope", arg.Warnings)
+	}
+}
@@ -0,0 +1,237 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/hertz/cmd/hz/meta"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ca-x/protoc-gen-go-hz/pkg/version"
+)
+
+// ManifestFile 项目清单文件名，与 hz 工具一致
+const ManifestFile = meta.ManifestFile
+
+// manifestTitle 清单文件头，使用YAML注释以便 hz 工具同样可以解析
+const manifestTitle = "# Code generated by protoc-gen-go-hz. DO NOT EDIT."
+
+// hzManifestTitle hz 写入的清单文件头，不是合法的YAML，解析前需去掉
+const hzManifestTitle = "// Code generated by hz. DO NOT EDIT."
+
+// Manifest 项目清单，在 new 时写入项目根目录，后续运行据此确定为 update
+// 并复用最初的目录与生成选项。前四个字段与 hz 的 .hz 文件兼容。
+type Manifest struct {
+	Version       string            `yaml:"hz version"`
+	HandlerDir    string            `yaml:"handlerDir"`
+	ModelDir      string            `yaml:"modelDir"`
+	RouterDir     string            `yaml:"routerDir"`
+	PluginVersion string            `yaml:"protoc-gen-go-hz version,omitempty"`
+	Options       map[string]string `yaml:"options,omitempty"`
+}
+
+// manifestSkipParams 不记录到清单中的参数：目录单独记录，其余只影响单次运行
+var manifestSkipParams = map[string]bool{
+	"out_dir":        true,
+	"handler_dir":    true,
+	"model_dir":      true,
+	"router_dir":     true,
+	"verbose":        true,
+	"strict":         true,
+	"config":         true,
+	"cmd_type":       true,
+	"version_check":  true,
+	"verify":         true,
+	"dry_run":        true,
+	"dry_run_report": true,
+}
+
+// LoadManifest 读取 dir 下的清单文件，文件不存在时返回 nil
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s failed: %v", ManifestFile, err)
+	}
+
+	var m Manifest
+	data = bytes.TrimPrefix(data, []byte(hzManifestTitle))
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("decode %s failed: %v", ManifestFile, err)
+	}
+	if m.Version == "" {
+		return nil, fmt.Errorf("%s has no hz version, the project was not created by hz or protoc-gen-go-hz", ManifestFile)
+	}
+	return &m, nil
+}
+
+// NewManifest 根据本次运行的参数创建清单
+func (arg *Argument) NewManifest() *Manifest {
+	m := &Manifest{
+		Version:       meta.Version,
+		HandlerDir:    arg.HandlerDir,
+		ModelDir:      arg.ModelDir,
+		RouterDir:     arg.RouterDir,
+		PluginVersion: version.Version,
+	}
+	for key, value := range arg.explicit {
+		if manifestSkipParams[key] {
+			continue
+		}
+		if m.Options == nil {
+			m.Options = make(map[string]string)
+		}
+		m.Options[key] = value
+	}
+	return m
+}
+
+// UpdateManifest 将本次显式给出的选项与运行的插件版本记录到已有的清单中，
+// 使 update 时新增或修改的选项在之后的运行中生效。返回清单是否发生变化。
+func (arg *Argument) UpdateManifest(m *Manifest) bool {
+	changed := false
+	dirs := []struct {
+		field    *string
+		recorded *string
+	}{
+		{&arg.HandlerDir, &m.HandlerDir},
+		{&arg.ModelDir, &m.ModelDir},
+		{&arg.RouterDir, &m.RouterDir},
+	}
+	for _, d := range dirs {
+		if *d.recorded == "" && *d.field != "" {
+			*d.recorded = *d.field
+			changed = true
+		}
+	}
+	for key, value := range arg.explicit {
+		if manifestSkipParams[key] {
+			continue
+		}
+		if recorded, ok := m.Options[key]; ok && recorded == value {
+			continue
+		}
+		if m.Options == nil {
+			m.Options = make(map[string]string)
+		}
+		m.Options[key] = value
+		changed = true
+	}
+	if m.PluginVersion != version.Version {
+		m.PluginVersion = version.Version
+		changed = true
+	}
+	return changed
+}
+
+// ApplyManifest 复用清单中记录的目录与选项。命令行或配置文件显式给出的选项优先，
+// 但显式给出的目录与清单不一致时返回错误，避免 update 把代码生成到另一套目录中。
+func (arg *Argument) ApplyManifest(m *Manifest) error {
+	dirs := []struct {
+		key      string
+		field    *string
+		recorded string
+	}{
+		{"handler_dir", &arg.HandlerDir, m.HandlerDir},
+		{"model_dir", &arg.ModelDir, m.ModelDir},
+		{"router_dir", &arg.RouterDir, m.RouterDir},
+	}
+	for _, d := range dirs {
+		if d.recorded == "" {
+			continue
+		}
+		if _, ok := arg.explicit[d.key]; ok && filepath.Clean(*d.field) != filepath.Clean(d.recorded) {
+			return fmt.Errorf("%s=%s conflicts with %s recorded in %s, remove the option or edit %s",
+				d.key, *d.field, d.recorded, ManifestFile, ManifestFile)
+		}
+		*d.field = d.recorded
+	}
+
+	keys := make([]string, 0, len(m.Options))
+	for key := range m.Options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := arg.explicit[key]; ok || manifestSkipParams[key] {
+			continue
+		}
+		if err := arg.parseParam(key + "=" + m.Options[key]); err != nil {
+			return fmt.Errorf("%s: %v", ManifestFile, err)
+		}
+	}
+	return nil
+}
+
+// Encode 清单文件的内容
+func (m *Manifest) Encode() (string, error) {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	return manifestTitle + "\n\n" + string(data), nil
+}
+
+// Persist 将清单写入 dir
+func (m *Manifest) Persist(dir string) error {
+	content, err := m.Encode()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), []byte(content), 0o644); err != nil {
+		return fmt.Errorf("write %s failed: %v", ManifestFile, err)
+	}
+	return nil
+}
+
+// recordParam 记录显式给出的参数，列表参数的多次取值以逗号合并
+func (arg *Argument) recordParam(param string) {
+	key, value, _ := strings.Cut(param, "=")
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if !isKnownParam(key) {
+		return
+	}
+	if arg.explicit == nil {
+		arg.explicit = make(map[string]string)
+	}
+	if prev, ok := arg.explicit[key]; ok && listParams[key] {
+		value = prev + "," + value
+	}
+	arg.explicit[key] = value
+}
+
+// isKnownParam 判断是否为本插件支持的参数
+func isKnownParam(key string) bool {
+	if strings.HasPrefix(key, "option_package:") {
+		return true
+	}
+	for _, p := range knownParams {
+		if p == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,234 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/cmd/hz/meta"
+
+	"github.com/ca-x/protoc-gen-go-hz/pkg/version"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	arg := &Argument{}
+	if err := arg.Unpack([]string{"handler_dir=api/handler", "client_dir=biz/client", "otel=true",
+		"exclude_file=a.proto", "exclude_file=b.proto", "verbose=true", "dry_run=false"}); err != nil {
+		t.Fatal(err)
+	}
+	m := arg.NewManifest()
+	want := map[string]string{"client_dir": "biz/client", "otel": "true", "exclude_file": "a.proto,b.proto"}
+	if !reflect.DeepEqual(m.Options, want) {
+		t.Errorf("Options = %v, want %v", m.Options, want)
+	}
+
+	dir := t.TempDir()
+	if err := m.Persist(dir); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, m) {
+		t.Errorf("LoadManifest = %+v, want %+v", loaded, m)
+	}
+}
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+	if m, err := LoadManifest(dir); m != nil || err != nil {
+		t.Fatalf("missing manifest: %v, %v", m, err)
+	}
+
+	// hz 写入的清单没有插件版本与选项
+	content := "// Code generated by hz. DO NOT EDIT.\n\nhz version: v0.9.0\nhandlerDir: h\nmodelDir: m\nrouterDir: r\n"
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != "v0.9.0" || m.HandlerDir != "h" || m.ModelDir != "m" || m.RouterDir != "r" || m.Options != nil {
+		t.Errorf("LoadManifest = %+v", m)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), []byte("handlerDir: h\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadManifest(dir); err == nil || !strings.Contains(err.Error(), "has no hz version") {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func TestUpdateManifest(t *testing.T) {
+	recorded := func() *Manifest {
+		return &Manifest{
+			Version: meta.Version, HandlerDir: "biz/handler", ModelDir: "biz/model", RouterDir: "biz/router",
+			PluginVersion: version.Version,
+			Options:       map[string]string{"otel": "true"},
+		}
+	}
+	tests := []struct {
+		name     string
+		params   []string
+		manifest func() *Manifest
+		changed  bool
+		options  map[string]string
+	}{
+		{name: "no options", manifest: recorded, options: map[string]string{"otel": "true"}},
+		{name: "same option", params: []string{"otel=true", "verbose=true"}, manifest: recorded, options: map[string]string{"otel": "true"}},
+		{name: "new option", params: []string{"envelope=code_msg_data"}, manifest: recorded, changed: true,
+			options: map[string]string{"otel": "true", "envelope": "code_msg_data"}},
+		{name: "changed option", params: []string{"otel=false"}, manifest: recorded, changed: true,
+			options: map[string]string{"otel": "false"}},
+		{name: "plugin version", manifest: func() *Manifest {
+			m := recorded()
+			m.PluginVersion = "v0.0.1"
+			return m
+		}, changed: true, options: map[string]string{"otel": "true"}},
+		{name: "hz manifest", params: []string{"client_dir=biz/client"}, manifest: func() *Manifest {
+			return &Manifest{Version: meta.Version, HandlerDir: "biz/handler", ModelDir: "biz/model", RouterDir: "biz/router"}
+		}, changed: true, options: map[string]string{"client_dir": "biz/client"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arg := &Argument{}
+			if err := arg.Unpack(tt.params); err != nil {
+				t.Fatal(err)
+			}
+			m := tt.manifest()
+			if err := arg.ApplyManifest(m); err != nil {
+				t.Fatal(err)
+			}
+			if changed := arg.UpdateManifest(m); changed != tt.changed {
+				t.Errorf("UpdateManifest() = %v, want %v", changed, tt.changed)
+			}
+			if !reflect.DeepEqual(m.Options, tt.options) || m.PluginVersion != version.Version {
+				t.Errorf("manifest options %v version %q, want %v %q", m.Options, m.PluginVersion, tt.options, version.Version)
+			}
+		})
+	}
+}
+
+func TestApplyManifest(t *testing.T) {
+	manifest := &Manifest{
+		Version:    meta.Version,
+		HandlerDir: "api/handler",
+		ModelDir:   "api/model",
+		RouterDir:  "api/router",
+		Options: map[string]string{
+			"client_dir":   "biz/client",
+			"otel":         "true",
+			"envelope":     "code_msg_data",
+			"exclude_file": "a.proto,b.proto",
+			"verbose":      "true",
+		},
+	}
+
+	tests := []struct {
+		name   string
+		params []string
+		check  func(t *testing.T, arg *Argument)
+		err    string
+	}{
+		{
+			name: "reuse recorded directories and options",
+			check: func(t *testing.T, arg *Argument) {
+				if arg.HandlerDir != "api/handler" || arg.ModelDir != "api/model" || arg.RouterDir != "api/router" {
+					t.Errorf("dirs = %q %q %q", arg.HandlerDir, arg.ModelDir, arg.RouterDir)
+				}
+				if arg.ClientDir != "biz/client" || !arg.OTel || arg.Envelope != "code_msg_data" {
+					t.Errorf("options not applied: client_dir=%q otel=%v envelope=%q", arg.ClientDir, arg.OTel, arg.Envelope)
+				}
+				if want := []string{"a.proto", "b.proto"}; !reflect.DeepEqual(arg.Excludes, want) {
+					t.Errorf("Excludes = %q, want %q", arg.Excludes, want)
+				}
+				if arg.Verbose {
+					t.Error("verbose only affects a single run and must not be reused")
+				}
+			},
+		},
+		{
+			name:   "explicit options win",
+			params: []string{"otel=false", "envelope=none", "exclude_file=c.proto"},
+			check: func(t *testing.T, arg *Argument) {
+				if arg.OTel || arg.Envelope != "none" {
+					t.Errorf("otel=%v envelope=%q, explicit options should override the manifest", arg.OTel, arg.Envelope)
+				}
+				if want := []string{"c.proto"}; !reflect.DeepEqual(arg.Excludes, want) {
+					t.Errorf("Excludes = %q, want %q", arg.Excludes, want)
+				}
+			},
+		},
+		{
+			name:   "same directory spelled differently",
+			params: []string{"handler_dir=./api/handler/"},
+			check: func(t *testing.T, arg *Argument) {
+				if arg.HandlerDir != "api/handler" {
+					t.Errorf("HandlerDir = %q", arg.HandlerDir)
+				}
+			},
+		},
+		{
+			name:   "conflicting handler_dir",
+			params: []string{"handler_dir=biz/handler"},
+			err:    "handler_dir=biz/handler conflicts with api/handler recorded in .hz",
+		},
+		{
+			name:   "conflicting router_dir",
+			params: []string{"router_dir=router"},
+			err:    "router_dir=router conflicts with api/router",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			arg := &Argument{}
+			if err := arg.Unpack(tt.params); err != nil {
+				t.Fatal(err)
+			}
+			err := arg.ApplyManifest(manifest)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("ApplyManifest error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, arg)
+		})
+	}
+}
+
+func TestApplyManifestInvalidOption(t *testing.T) {
+	arg := &Argument{}
+	if err := arg.Unpack([]string{"strict=true"}); err != nil {
+		t.Fatal(err)
+	}
+	m := &Manifest{Version: meta.Version, Options: map[string]string{"client_codec": "xml"}}
+	if err := arg.ApplyManifest(m); err == nil || !strings.Contains(err.Error(), ManifestFile+`: invalid value "xml" for client_codec`) {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -17,8 +17,15 @@
 package generator
 
 import (
+	"bytes"
 	"fmt"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
+	"unicode"
 
 	"github.com/ca-x/protoc-gen-go-hz/pkg/version"
 	"github.com/cloudwego/hertz/cmd/hz/generator/model"
@@ -40,6 +47,12 @@ type HTTPPackageGenerator struct {
 	QueryEnumAsInt   bool
 	ServiceGenDir    string
 	CustomizePackage string // 自定义包模板路径
+	ClientCodec      string // 客户端默认编码: json, protobuf, prototext
+	ProtoText        bool   // 是否支持protobuf文本格式
+	HandlerTests     bool   // 为每个服务生成handler测试脚手架
+	ClientMock       bool   // 为每个服务生成供客户端测试使用的mock实现
+	OTel             bool   // 为路由生成OpenTelemetry追踪与指标中间件，客户端传播追踪上下文
+	RoutesJSON       bool   // 在router目录输出供工具读取的routes.json
 
 	NeedModel            bool
 	HandlerByMethod      bool
@@ -59,6 +72,9 @@ type HTTPPackage struct {
 	Services   []*Service
 	Models     []*model.Model
 	RouterInfo *Router
+	ErrorEnums []*ErrorEnum // 带错误码注解的枚举
+	// 共用一个Hertz路由的绑定分组，由 checkRoutes 生成
+	RouteGroups []*RouteGroup
 }
 
 // Service 服务结构
@@ -70,15 +86,129 @@ type Service struct {
 	BaseDomain    string
 	ServiceGroup  string
 	ServiceGenDir string
+	Envelope      string // JSON响应包装格式，空表示不包装
+	Comment       string // proto注释，每行不含注释符
+	Deprecated    bool   // 服务标记为 deprecated = true
 }
 
 // HTTPMethod HTTP方法结构
 type HTTPMethod struct {
 	Name         string
-	HTTPMethod   string
-	Path         string
+	HTTPMethod   string     // 首个路由的HTTP方法
+	Path         string     // 首个路由的Hertz路径
+	Bindings     []*Binding // 方法的所有路由，首个为 google.api.http 的主规则，其后为 additional_bindings
 	RequestType  string
 	ResponseType string
+	ServerStream bool          // 服务端流式或双向流式方法
+	ClientStream bool          // 客户端流式或双向流式方法
+	Comment      string        // proto注释，每行不含注释符
+	Deprecated   bool          // 方法或所属服务标记为 deprecated = true
+	Sunset       string        // (hz.sunset) 对应的HTTP日期，作为Sunset响应头
+	Deprecation  int64         // (hz.deprecation) 的Unix时间（秒），作为Deprecation响应头，0表示未设置
+	ProtoName    string        // proto方法全名，如 users.v1.Users.GetUser
+	Auth         *Auth         // 需要的认证，nil表示公开
+	Timeout      time.Duration // (hz.timeout) 调用超时，0表示不限制
+	MaxBodySize  int64         // (hz.max_body_size) 请求体最大字节数，0表示不限制
+
+	SampleRequest string // 示例请求的Go字面量，模型包以 model 限定，用于handler测试脚手架
+	SamplePath    string // 首个路由填入示例路径变量后的请求路径，用于handler测试脚手架
+}
+
+// Binding 方法的一个HTTP路由，每个路由生成一次注册与一个handler适配函数
+type Binding struct {
+	HTTPMethod string       // HTTP方法，如 GET
+	Path       string       // Hertz路由路径，如 /v1/shelves/:shelf/books/:id
+	Template   string       // google.api.http 中的路径模板，如 /v1/shelves/{shelf}/books/{id}
+	Body       string       // 请求体对应的字段: * 为整个请求，空为无请求体，其余为顶层字段名
+	BodyField  string       // Body为字段名时该字段的Go名，如 Book
+	Handler    string       // handler适配函数名，首个路由为方法名，其余为 <Method>Binding<N>
+	Params     []*PathParam // 路径变量，按出现顺序
+	Segments   []*Segment   // 路径模板按字面量与变量切分，客户端据此构造请求路径
+	// 路径模板末尾的自定义方法，如 /v1/{name=books/*}:archive 中的 archive
+	Verb string
+	// 动词前为字面量时（如 /v1/books:batchGet 中的 books），Hertz无法匹配该段，
+	// 以一个不绑定字段的参数代替，由分发路由比较字面量
+	VerbLiteral string
+	Group       *RouteGroup // 与其他绑定共用的路由注册，nil表示单独注册
+	Dispatch    bool        // 在该绑定的位置注册Group的分发路由，即Group中第一个绑定
+}
+
+// RouteGroup 共用一个Hertz路由注册的绑定：Hertz把 : 视为参数标记，无法匹配 :verb 后缀，
+// 因此带动词的绑定以去掉后缀的路径注册，由分发路由按末段参数的后缀选择绑定；
+// 同形状、不带动词的绑定处理没有匹配动词的请求
+type RouteGroup struct {
+	HTTPMethod string
+	Path       string // 注册的Hertz路径
+	Param      string // 末段参数名，其值带有 :verb 后缀
+	Members    []*RouteMember
+}
+
+// RouteMember 分组中的一个绑定
+type RouteMember struct {
+	Service *Service
+	Method  *HTTPMethod
+	Binding *Binding
+	Index   int // 在生成的路由表中的位置
+}
+
+// PathParam 绑定到请求字段的路径变量，多段变量（如 {name=shelves/*}）由字面量与多个Hertz参数拼接
+type PathParam struct {
+	Field string      // 请求字段路径，如 book.name
+	Parts []*PathPart // 组成字段值的片段
+}
+
+// PathPart 路径变量值的一个片段：字面量，或Hertz路由参数
+type PathPart struct {
+	Literal string
+	Param   string
+}
+
+// Segment 路径模板的一个片段：字面量，或绑定请求字段的变量
+type Segment struct {
+	Literal string
+	Field   string // 变量绑定的请求字段路径
+	Multi   bool   // 变量值可以包含 /，如 {name=shelves/*} 或 {path=**}
+}
+
+// QueryExclude 客户端发送查询参数时排除的字段：路径变量与请求体字段
+func (b *Binding) QueryExclude() []string {
+	var fields []string
+	for _, p := range b.Params {
+		fields = append(fields, p.Field)
+	}
+	if b.BodyField != "" {
+		fields = append(fields, b.Body)
+	}
+	return fields
+}
+
+// Auth (hz.auth)/(hz.default_auth) 声明的认证要求
+type Auth struct {
+	Scheme string   // 凭证类型，如 bearer
+	Scopes []string // 调用方必须具备的scope
+}
+
+// SSE 是否为仅服务端流式方法，通过SSE推送响应
+func (m *HTTPMethod) SSE() bool {
+	return m.ServerStream && !m.ClientStream
+}
+
+// WebSocket 是否为客户端流式或双向流式方法，通过WebSocket传输
+func (m *HTTPMethod) WebSocket() bool {
+	return m.ClientStream
+}
+
+// Streaming 方法的流式类型: server, client, bidi，非流式方法为空
+func (m *HTTPMethod) Streaming() string {
+	switch {
+	case m.ServerStream && m.ClientStream:
+		return "bidi"
+	case m.ServerStream:
+		return "server"
+	case m.ClientStream:
+		return "client"
+	}
+	return ""
 }
 
 // ClientMethod 客户端方法结构
@@ -90,13 +220,162 @@ type ClientMethod struct {
 	ResponseType string
 }
 
+// ErrorEnum 带 (errors.default_code)/(errors.code) 注解的错误原因枚举
+type ErrorEnum struct {
+	Name    string // proto全名，作为ErrorInfo的domain
+	Reasons []*ErrorReason
+}
+
+// ErrorReason 错误原因枚举值
+type ErrorReason struct {
+	Name       string // 枚举值名，如 USER_NOT_FOUND
+	GoName     string // 生成函数名后缀，如 UserNotFound
+	HTTPStatus int    // 返回的HTTP状态码
+}
+
+// HasSSE 服务是否包含通过SSE传输的方法
+func (s *Service) HasSSE() bool {
+	for _, m := range s.Methods {
+		if m.SSE() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasUnary 服务是否包含非流式方法
+func (s *Service) HasUnary() bool {
+	for _, m := range s.Methods {
+		if !m.ServerStream && !m.ClientStream {
+			return true
+		}
+	}
+	return false
+}
+
+// HasUnaryTimeout 服务是否包含声明了超时的非流式方法，客户端为其设置超时
+func (s *Service) HasUnaryTimeout() bool {
+	for _, m := range s.Methods {
+		if m.Timeout > 0 && !m.ServerStream && !m.ClientStream {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAuth 服务是否包含需要认证的方法
+func (s *Service) HasAuth() bool {
+	for _, m := range s.Methods {
+		if m.Auth != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// HasWebSocket 服务是否包含通过WebSocket传输的方法
+func (s *Service) HasWebSocket() bool {
+	for _, m := range s.Methods {
+		if m.WebSocket() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSSE 包内是否有服务包含通过SSE传输的方法
+func (p *HTTPPackage) HasSSE() bool {
+	for _, s := range p.Services {
+		if s.HasSSE() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAuth 包内是否有服务包含需要认证的方法
+func (p *HTTPPackage) HasAuth() bool {
+	for _, s := range p.Services {
+		if s.HasAuth() {
+			return true
+		}
+	}
+	return false
+}
+
+// Scopes 所有认证要求中出现的scope，去重并排序
+func (p *HTTPPackage) Scopes() []string {
+	seen := map[string]bool{}
+	var scopes []string
+	for _, s := range p.Services {
+		for _, m := range s.Methods {
+			if m.Auth == nil {
+				continue
+			}
+			for _, scope := range m.Auth.Scopes {
+				if !seen[scope] {
+					seen[scope] = true
+					scopes = append(scopes, scope)
+				}
+			}
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// HasWebSocket 包内是否有服务包含通过WebSocket传输的方法
+func (p *HTTPPackage) HasWebSocket() bool {
+	for _, s := range p.Services {
+		if s.HasWebSocket() {
+			return true
+		}
+	}
+	return false
+}
+
 // Router 路由信息
 type Router struct {
 	Registers []string
 }
 
+// envelopes 响应包装格式到codec包常量名的映射
+var envelopes = map[string]string{
+	"none":          "EnvelopeNone",
+	"code_msg_data": "EnvelopeCodeMsgData",
+}
+
+// CheckEnvelope 校验响应包装格式名称
+func CheckEnvelope(name string) error {
+	if _, ok := envelopes[name]; !ok {
+		return fmt.Errorf("unsupported envelope %q, expected none or code_msg_data", name)
+	}
+	return nil
+}
+
+// clientCodecs 客户端编码选项到codec包常量名的映射
+var clientCodecs = map[string]string{
+	"json":      "MIMEJSON",
+	"protobuf":  "MIMEProtobuf",
+	"prototext": "MIMEProtoText",
+}
+
 // Init 初始化生成器
 func (pkgGen *HTTPPackageGenerator) Init() error {
+	// 校验客户端默认编码
+	if pkgGen.ClientCodec == "" {
+		pkgGen.ClientCodec = "json"
+	}
+	if _, ok := clientCodecs[pkgGen.ClientCodec]; !ok {
+		return fmt.Errorf("unsupported client_codec %q, expected json, protobuf or prototext", pkgGen.ClientCodec)
+	}
+	if pkgGen.ClientCodec == "prototext" && !pkgGen.ProtoText {
+		return fmt.Errorf("client_codec=prototext requires proto_text=true")
+	}
+	if pkgGen.ClientMock && pkgGen.ClientDir == "" {
+		return fmt.Errorf("client_mock=true requires client_dir")
+	}
+
 	// 加载自定义模板配置（如果指定）
 	if pkgGen.CustomizePackage != "" {
 		config, err := LoadCustomTemplate(pkgGen.CustomizePackage)
@@ -112,6 +391,11 @@ func (pkgGen *HTTPPackageGenerator) Init() error {
 func (pkgGen *HTTPPackageGenerator) Generate(httpPkg *HTTPPackage) ([]*GeneratedFile, error) {
 	var files []*GeneratedFile
 
+	// 路由冲突在生成时报告，而不是到服务启动时才panic
+	if err := checkRoutes(httpPkg); err != nil {
+		return nil, err
+	}
+
 	// 生成handler代码
 	handlerFiles, err := pkgGen.generateHandlers(httpPkg)
 	if err != nil {
@@ -142,16 +426,131 @@ func (pkgGen *HTTPPackageGenerator) generateHandlers(httpPkg *HTTPPackage) ([]*G
 
 	for _, service := range httpPkg.Services {
 		for _, method := range service.Methods {
+			content, err := pkgGen.generateHandlerCode(httpPkg, service, method)
+			if err != nil {
+				return nil, err
+			}
 			// 使用相对路径，符合protoc插件标准
 			path := pkgGen.HandlerDir + "/" + method.Name + ".go"
-			file := &GeneratedFile{
+			files = append(files, &GeneratedFile{
 				Path:    path,
-				Content: pkgGen.generateHandlerCode(httpPkg, service, method),
+				Content: content,
+			})
+		}
+	}
+
+	// 每个服务生成一个服务接口文件
+	for _, service := range httpPkg.Services {
+		data := pkgGen.newTemplateData(httpPkg)
+		data.Service = service
+		content, err := renderHTTPTemplate("service", serviceTemplate, data)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &GeneratedFile{
+			Path:    pkgGen.HandlerDir + "/" + service.Name + "_service.go",
+			Content: content,
+		})
+	}
+
+	// 按需为每个服务生成handler测试脚手架
+	if pkgGen.HandlerTests {
+		for _, service := range httpPkg.Services {
+			data := pkgGen.newTemplateData(httpPkg)
+			data.Service = service
+			content, err := renderHTTPTemplate("handler_test", handlerTestTemplate, data)
+			if err != nil {
+				return nil, err
 			}
-			files = append(files, file)
+			files = append(files, &GeneratedFile{
+				Path:    pkgGen.HandlerDir + "/" + service.Name + "_test.go",
+				Content: content,
+				Once:    true,
+			})
 		}
 	}
 
+	// 生成handler与client共用的内容协商包和错误模型包
+	for _, pkg := range []struct{ name, tpl, path string }{
+		{"codec", codecTemplate, pkgGen.siblingDir("codec") + "/codec.go"},
+		{"query", queryTemplate, pkgGen.siblingDir("codec") + "/query.go"},
+		{"errors", errorsTemplate, pkgGen.siblingDir("errors") + "/errors.go"},
+	} {
+		content, err := renderHTTPTemplate(pkg.name, pkg.tpl, pkgGen.newTemplateData(httpPkg))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &GeneratedFile{
+			Path:    pkg.path,
+			Content: content,
+		})
+	}
+
+	// 按需生成流式方法的SSE与WebSocket适配
+	for _, transport := range []struct {
+		name, tpl string
+		used      bool
+	}{
+		{"sse", sseTemplate, httpPkg.HasSSE()},
+		{"websocket", websocketTemplate, httpPkg.HasWebSocket()},
+	} {
+		if !transport.used {
+			continue
+		}
+		content, err := renderHTTPTemplate(transport.name, transport.tpl, pkgGen.newTemplateData(httpPkg))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &GeneratedFile{
+			Path:    pkgGen.siblingDir("stream") + "/" + transport.name + ".go",
+			Content: content,
+		})
+	}
+
+	// 按需生成认证中间件与受保护路由表
+	if httpPkg.HasAuth() {
+		consts := map[string]string{}
+		for _, scope := range httpPkg.Scopes() {
+			name := scopeConst(scope)
+			if other, ok := consts[name]; ok {
+				return nil, fmt.Errorf("scopes %q and %q both map to the constant %s", other, scope, name)
+			}
+			consts[name] = scope
+		}
+		content, err := renderHTTPTemplate("auth", authTemplate, pkgGen.newTemplateData(httpPkg))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &GeneratedFile{
+			Path:    pkgGen.siblingDir("auth") + "/auth.go",
+			Content: content,
+		})
+	}
+
+	// 按需生成路由的追踪与指标中间件
+	if pkgGen.OTel {
+		content, err := renderHTTPTemplate("telemetry", telemetryTemplate, pkgGen.newTemplateData(httpPkg))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &GeneratedFile{
+			Path:    pkgGen.siblingDir("telemetry") + "/telemetry.go",
+			Content: content,
+		})
+	}
+
+	// 生成错误枚举对应的构造函数与判断函数
+	if len(httpPkg.ErrorEnums) > 0 {
+		content, err := renderHTTPTemplate("reasons", reasonsTemplate, pkgGen.newTemplateData(httpPkg))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &GeneratedFile{
+			Path:    pkgGen.siblingDir("errors") + "/reasons.go",
+			Content: content,
+		})
+	}
+
 	return files, nil
 }
 
@@ -160,22 +559,57 @@ func (pkgGen *HTTPPackageGenerator) generateRouters(httpPkg *HTTPPackage) ([]*Ge
 	var files []*GeneratedFile
 
 	// 检查是否有自定义模板覆盖 router.go
+	var custom *CustomTemplate
 	if pkgGen.customTemplates != nil {
-		for _, tpl := range pkgGen.customTemplates.Layouts {
+		for i, tpl := range pkgGen.customTemplates.Layouts {
 			if tpl.Path == "router.go" && !tpl.Disable {
-				// 使用自定义模板
-				return pkgGen.generateRoutersWithCustomTemplate(httpPkg, &tpl)
+				custom = &pkgGen.customTemplates.Layouts[i]
+				break
 			}
 		}
 	}
 
-	// 使用默认模板
-	path := pkgGen.RouterDir + "/router.go"
-	file := &GeneratedFile{
-		Path:    path,
-		Content: pkgGen.generateRouterCode(httpPkg),
+	if custom != nil {
+		// 使用自定义模板
+		routerFiles, err := pkgGen.generateRoutersWithCustomTemplate(httpPkg, custom)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, routerFiles...)
+	} else {
+		// 使用默认模板
+		content, err := pkgGen.generateRouterCode(httpPkg)
+		if err != nil {
+			return nil, err
+		}
+		path := pkgGen.RouterDir + "/router.go"
+		file := &GeneratedFile{
+			Path:    path,
+			Content: content,
+		}
+		files = append(files, file)
+	}
+
+	// 路由表与路由查找，自定义router模板时同样生成
+	content, err := renderHTTPTemplate("routes", routesTemplate, pkgGen.newTemplateData(httpPkg))
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, &GeneratedFile{
+		Path:    pkgGen.RouterDir + "/routes.go",
+		Content: content,
+	})
+
+	if pkgGen.RoutesJSON {
+		content, err := routesJSON(httpPkg)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &GeneratedFile{
+			Path:    pkgGen.RouterDir + "/routes.json",
+			Content: content,
+		})
 	}
-	files = append(files, file)
 
 	return files, nil
 }
@@ -219,20 +653,110 @@ func (pkgGen *HTTPPackageGenerator) generateClients(httpPkg *HTTPPackage) ([]*Ge
 	}
 
 	for _, service := range httpPkg.Services {
+		content, err := pkgGen.generateClientCode(httpPkg, service)
+		if err != nil {
+			return nil, err
+		}
 		// 使用相对路径，符合protoc插件标准
 		path := pkgGen.ClientDir + "/" + service.Name + "_client.go"
-		file := &GeneratedFile{
+		files = append(files, &GeneratedFile{
 			Path:    path,
-			Content: pkgGen.generateClientCode(service),
+			Content: content,
+		})
+	}
+
+	// 生成client包共享的选项与请求发送逻辑
+	content, err := renderHTTPTemplate("client_options", clientOptionsTemplate, pkgGen.newTemplateData(httpPkg))
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, &GeneratedFile{
+		Path:    pkgGen.ClientDir + "/client.go",
+		Content: content,
+	})
+
+	if pkgGen.ClientMock {
+		mockFiles, err := pkgGen.generateMocks(httpPkg)
+		if err != nil {
+			return nil, err
 		}
-		files = append(files, file)
+		files = append(files, mockFiles...)
 	}
 
 	return files, nil
 }
 
+// generateMocks 在client目录下的mock包中为每个服务生成mock实现，以及共享的测试服务器
+func (pkgGen *HTTPPackageGenerator) generateMocks(httpPkg *HTTPPackage) ([]*GeneratedFile, error) {
+	mockDir := pkgGen.ClientDir + "/mock"
+	var files []*GeneratedFile
+	for _, service := range httpPkg.Services {
+		data := pkgGen.newTemplateData(httpPkg)
+		data.Service = service
+		content, err := renderHTTPTemplate("mock", mockTemplate, data)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &GeneratedFile{
+			Path:    mockDir + "/" + service.Name + "_mock.go",
+			Content: content,
+		})
+	}
+
+	content, err := renderHTTPTemplate("mock_server", mockServerTemplate, pkgGen.newTemplateData(httpPkg))
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, &GeneratedFile{
+		Path:    mockDir + "/server.go",
+		Content: content,
+	})
+	return files, nil
+}
+
 // generateHandlerCode 生成单个handler的代码
-func (pkgGen *HTTPPackageGenerator) generateHandlerCode(httpPkg *HTTPPackage, service *Service, method *HTTPMethod) string {
+func (pkgGen *HTTPPackageGenerator) generateHandlerCode(httpPkg *HTTPPackage, service *Service, method *HTTPMethod) (string, error) {
+	data := pkgGen.newTemplateData(httpPkg)
+	data.Service = service
+	data.Method = method
+	return renderHTTPTemplate("handler", handlerTemplate, data)
+}
+
+// generateRouterCode 生成router代码
+func (pkgGen *HTTPPackageGenerator) generateRouterCode(httpPkg *HTTPPackage) (string, error) {
+	return renderHTTPTemplate("router", routerTemplate, pkgGen.newTemplateData(httpPkg))
+}
+
+// generateClientCode 生成client代码
+func (pkgGen *HTTPPackageGenerator) generateClientCode(httpPkg *HTTPPackage, service *Service) (string, error) {
+	data := pkgGen.newTemplateData(httpPkg)
+	data.Service = service
+	return renderHTTPTemplate("client", clientTemplate, data)
+}
+
+// httpTemplateData HTTP代码模板渲染数据
+type httpTemplateData struct {
+	Version         string
+	Package         *HTTPPackage
+	Service         *Service
+	Method          *HTTPMethod
+	ModelImport     string // model包导入路径
+	ModelPkgName    string // model包名
+	HandlerImport   string // handler包导入路径
+	RouterImport    string // router包导入路径
+	ClientImport    string // client包导入路径
+	AuthImport      string // 认证包导入路径
+	CodecImport     string // 内容协商包导入路径
+	ErrorsImport    string // 错误模型包导入路径
+	StreamImport    string // 流式传输适配包导入路径
+	TelemetryImport string // 可观测性包导入路径
+	ClientCodec     string // 客户端默认编码对应的codec常量名
+	ProtoText       bool   // 是否支持protobuf文本格式
+	OTel            bool   // 是否生成OpenTelemetry埋点
+}
+
+// newTemplateData 构建HTTP代码模板的公共渲染数据
+func (pkgGen *HTTPPackageGenerator) newTemplateData(httpPkg *HTTPPackage) *httpTemplateData {
 	// 使用 ModelPkg 如果有，否则回退到默认路径
 	modelImport := httpPkg.ModelPkg
 	if modelImport == "" {
@@ -245,105 +769,175 @@ func (pkgGen *HTTPPackageGenerator) generateHandlerCode(httpPkg *HTTPPackage, se
 		modelPkgName = modelImport[idx+1:]
 	}
 
-	return `// Code generated by protoc-gen-go-hz ` + version.Version + `. DO NOT EDIT.
-
-package handler
-
-import (
-    "context"
-    "github.com/cloudwego/hertz/pkg/app"
-    "` + modelImport + `"
-)
-
-// ` + method.Name + ` .
-func ` + method.Name + `(ctx context.Context, c *app.RequestContext) {
-    var err error
-    var req ` + modelPkgName + `.` + method.RequestType + `
-    err = c.BindAndValidate(&req)
-    if err != nil {
-        c.JSON(400, map[string]interface{}{
-            "error": err.Error(),
-        })
-        return
-    }
-
-    // TODO: implement your business logic here
-    resp := &` + modelPkgName + `.` + method.ResponseType + `{}
-    c.JSON(200, resp)
-}
-`
+	return &httpTemplateData{
+		Version:         version.Version,
+		Package:         httpPkg,
+		ModelImport:     modelImport,
+		ModelPkgName:    modelPkgName,
+		HandlerImport:   pkgGen.ProjPackage + "/" + pkgGen.HandlerDir,
+		RouterImport:    pkgGen.ProjPackage + "/" + pkgGen.RouterDir,
+		ClientImport:    pkgGen.ProjPackage + "/" + pkgGen.ClientDir,
+		AuthImport:      pkgGen.ProjPackage + "/" + pkgGen.siblingDir("auth"),
+		CodecImport:     pkgGen.ProjPackage + "/" + pkgGen.siblingDir("codec"),
+		ErrorsImport:    pkgGen.ProjPackage + "/" + pkgGen.siblingDir("errors"),
+		StreamImport:    pkgGen.ProjPackage + "/" + pkgGen.siblingDir("stream"),
+		TelemetryImport: pkgGen.ProjPackage + "/" + pkgGen.siblingDir("telemetry"),
+		ClientCodec:     clientCodecs[pkgGen.ClientCodec],
+		ProtoText:       pkgGen.ProtoText,
+		OTel:            pkgGen.OTel,
+	}
 }
 
-// generateRouterCode 生成router代码
-func (pkgGen *HTTPPackageGenerator) generateRouterCode(httpPkg *HTTPPackage) string {
-	code := `// Code generated by protoc-gen-go-hz ` + version.Version + `. DO NOT EDIT.
-
-package router
-
-import (
-    "github.com/cloudwego/hertz/pkg/app/server"
-    "` + pkgGen.ProjPackage + `/biz/handler"
-)
+// siblingDir 与handler目录同级的生成包目录，如 biz/codec、biz/errors
+func (pkgGen *HTTPPackageGenerator) siblingDir(name string) string {
+	return path.Join(path.Dir(pkgGen.HandlerDir), name)
+}
 
-// Register registers HTTP handlers.
-func Register(r *server.Hertz) {
-`
+// templateFuncs HTTP代码模板可用的函数
+var templateFuncs = template.FuncMap{
+	"serviceVar":      serviceVar,
+	"envelopeConst":   func(name string) string { return envelopes[name] },
+	"streamVar":       streamVar,
+	"comment":         comment,
+	"rpcName":         rpcName,
+	"paramExpr":       paramExpr,
+	"register":        register,
+	"clientPath":      clientPath,
+	"scopeConst":      scopeConst,
+	"durationLiteral": durationLiteral,
+}
 
-	for _, service := range httpPkg.Services {
-		for _, method := range service.Methods {
-			code += `    r.` + method.HTTPMethod + `("` + method.Path + `", handler.` + method.Name + `)
-`
+// durationLiteral time.Duration 的Go表达式，使用能整除的最大单位，如 5 * time.Second
+func durationLiteral(d time.Duration) string {
+	for _, unit := range []struct {
+		d    time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	} {
+		if d%unit.d == 0 {
+			return fmt.Sprintf("%d * %s", d/unit.d, unit.name)
 		}
 	}
+	return fmt.Sprintf("%d * time.Nanosecond", int64(d))
+}
 
-	code += `}
-`
-	return code
+// paramExpr handler中路径变量值的Go表达式，如 "shelves/" + c.Param("book.name_1")
+func paramExpr(p *PathParam) string {
+	parts := make([]string, 0, len(p.Parts))
+	for _, part := range p.Parts {
+		if part.Param != "" {
+			parts = append(parts, fmt.Sprintf("c.Param(%q)", part.Param))
+		} else {
+			parts = append(parts, strconv.Quote(part.Literal))
+		}
+	}
+	return strings.Join(parts, " + ")
 }
 
-// generateClientCode 生成client代码
-func (pkgGen *HTTPPackageGenerator) generateClientCode(service *Service) string {
-	code := `// Code generated by protoc-gen-go-hz ` + version.Version + `. DO NOT EDIT.
+// register 注册路由的调用，Hertz没有快捷方法的HTTP方法（google.api.http 的 custom）使用 Handle，
+// 如 r.GET( 或 r.Handle("REPORT",
+func register(method string) string {
+	switch method {
+	case "GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS":
+		return "r." + method + "("
+	}
+	return fmt.Sprintf("r.Handle(%q, ", method)
+}
 
-package client
+// clientPath 客户端请求路径的Go表达式，路径变量取自 req，如 "/v1/books/" + codec.PathValue(req, "id", false)
+func clientPath(b *Binding) string {
+	parts := make([]string, 0, len(b.Segments))
+	for _, seg := range b.Segments {
+		if seg.Field != "" {
+			parts = append(parts, fmt.Sprintf("codec.PathValue(req, %q, %t)", seg.Field, seg.Multi))
+		} else {
+			parts = append(parts, strconv.Quote(seg.Literal))
+		}
+	}
+	return strings.Join(parts, " + ")
+}
 
-import (
-    "context"
-    "github.com/cloudwego/hertz/pkg/app/client"
-    "github.com/cloudwego/hertz/pkg/protocol"
-    "` + pkgGen.ProjPackage + `/biz/model"
-)
+// rpcName proto方法的gRPC风格全名，用作span名，如 greeter.Greeter.SayHello -> greeter.Greeter/SayHello
+func rpcName(protoName string) string {
+	if i := strings.LastIndex(protoName, "."); i >= 0 {
+		return protoName[:i] + "/" + protoName[i+1:]
+	}
+	return protoName
+}
 
-// ` + service.Name + `Client .
-type ` + service.Name + `Client struct {
-    client *client.Client
+// scopeConst scope在认证包中的常量名，如 user.read -> ScopeUserRead
+func scopeConst(scope string) string {
+	var b strings.Builder
+	b.WriteString("Scope")
+	upper := true
+	for _, r := range scope {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9':
+			if upper {
+				r = unicode.ToUpper(r)
+			}
+			b.WriteRune(r)
+			upper = false
+		default:
+			upper = true
+		}
+	}
+	return b.String()
 }
 
-// New` + service.Name + `Client creates a new ` + service.Name + `Client.
-func New` + service.Name + `Client(client *client.Client) *` + service.Name + `Client {
-    return &` + service.Name + `Client{
-        client: client,
-    }
+// comment 将proto注释渲染为Go注释行，deprecated时追加 Deprecated 段落；均为空时返回空
+func comment(text string, deprecated bool) string {
+	var lines []string
+	if text != "" {
+		for _, line := range strings.Split(text, "\n") {
+			lines = append(lines, strings.TrimRight("//"+line, " \t"))
+		}
+	}
+	if deprecated && !strings.Contains(text, "Deprecated:") {
+		if len(lines) > 0 {
+			lines = append(lines, "//")
+		}
+		lines = append(lines, "// Deprecated: Do not use.")
+	}
+	return strings.Join(lines, "\n")
 }
 
-`
+// serviceVar 服务实现在handler包中的变量名，如 Greeter -> greeterService
+func serviceVar(service string) string {
+	if service == "" {
+		return "service"
+	}
+	return strings.ToLower(service[:1]) + service[1:] + "Service"
+}
 
-	for _, method := range service.Methods {
-		code += `// ` + method.Name + ` calls ` + method.Name + ` endpoint.
-func (c *` + service.Name + `Client) ` + method.Name + `(ctx context.Context, req *model.` + method.RequestType + `) (*model.` + method.ResponseType + `, error) {
-    var resp model.` + method.ResponseType + `
-    err := c.client.` + method.HTTPMethod + `(ctx, nil, "` + method.Path + `", req, &resp)
-    return &resp, err
+// streamVar 流式方法在handler包中的流类型名，如 Greeter, Watch -> greeterWatchServer
+func streamVar(service, method string) string {
+	return strings.TrimSuffix(serviceVar(service), "Service") + method + "Server"
 }
 
-`
+// renderHTTPTemplate 渲染HTTP代码模板
+func renderHTTPTemplate(name, tplStr string, data interface{}) (string, error) {
+	tpl, err := template.New(name).Funcs(templateFuncs).Parse(tplStr)
+	if err != nil {
+		return "", fmt.Errorf("parse %s template failed: %v", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("execute %s template failed: %v", name, err)
 	}
 
-	return code
+	return buf.String(), nil
 }
 
 // GeneratedFile 生成的文件
 type GeneratedFile struct {
 	Path    string
 	Content string
+	Once    bool // 仅在文件不存在时生成，之后归用户所有，更新时不覆盖
 }
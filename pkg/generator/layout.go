@@ -93,14 +93,8 @@ func (lg *LayoutGenerator) GenerateByService(layout Layout) error {
 			continue
 		}
 
-		// 完整路径
-		fullPath := filepath.Join(layout.OutDir, filePath)
-
-		// 确保目录存在
-		dir := filepath.Dir(fullPath)
-		if !lg.dirs[dir] {
-			lg.dirs[dir] = true
-		}
+		// 记录需要创建的目录，与文件一样相对于OutputDir
+		lg.dirs[filepath.Dir(filePath)] = true
 
 		// 渲染文件内容（如果需要）
 		content := tpl.Body
@@ -122,10 +116,16 @@ func (lg *LayoutGenerator) GenerateByService(layout Layout) error {
 	return nil
 }
 
+// Files 生成的布局文件，路径相对于OutputDir
+func (lg *LayoutGenerator) Files() []GeneratedFile {
+	return lg.files
+}
+
 // Persist 持久化生成的文件
 func (lg *LayoutGenerator) Persist() error {
 	// 创建所有需要的目录
 	for dir := range lg.dirs {
+		dir = filepath.Join(lg.OutputDir, dir)
 		if err := os.MkdirAll(dir, 0o755); err != nil {
 			return fmt.Errorf("create directory %s failed: %v", dir, err)
 		}
@@ -0,0 +1,254 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// routeEntry routes.json中的一条路由，字段与生成的 router.RouteInfo 对应
+type routeEntry struct {
+	ProtoMethod string       `json:"proto_method"`
+	HTTPMethod  string       `json:"http_method"`
+	Path        string       `json:"path"`
+	Template    string       `json:"template"`
+	Verb        string       `json:"verb,omitempty"`
+	Body        string       `json:"body,omitempty"`
+	Handler     string       `json:"handler"`
+	Options     routeOptions `json:"options"`
+}
+
+// routeOptions 路由对应proto方法的选项，未设置的选项省略
+type routeOptions struct {
+	Streaming   string   `json:"streaming,omitempty"`
+	Deprecated  bool     `json:"deprecated,omitempty"`
+	Deprecation int64    `json:"deprecation,omitempty"`
+	Sunset      string   `json:"sunset,omitempty"`
+	Envelope    string   `json:"envelope,omitempty"`
+	AuthScheme  string   `json:"auth_scheme,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
+	Timeout     string   `json:"timeout,omitempty"`
+	MaxBodySize int64    `json:"max_body_size,omitempty"`
+}
+
+// routesJSON 按注册顺序输出所有路由，供网关配置、文档等工具读取
+func routesJSON(httpPkg *HTTPPackage) (string, error) {
+	routes := []routeEntry{}
+	for _, service := range httpPkg.Services {
+		for _, method := range service.Methods {
+			options := routeOptions{
+				Streaming:   method.Streaming(),
+				Deprecated:  method.Deprecated,
+				Deprecation: method.Deprecation,
+				Sunset:      method.Sunset,
+				Envelope:    service.Envelope,
+				MaxBodySize: method.MaxBodySize,
+			}
+			if method.Auth != nil {
+				options.AuthScheme = method.Auth.Scheme
+				options.Scopes = method.Auth.Scopes
+			}
+			if method.Timeout > 0 {
+				options.Timeout = method.Timeout.String()
+			}
+			for _, binding := range method.Bindings {
+				routes = append(routes, routeEntry{
+					ProtoMethod: method.ProtoName,
+					HTTPMethod:  binding.HTTPMethod,
+					Path:        binding.Path,
+					Template:    binding.Template,
+					Verb:        binding.Verb,
+					Body:        binding.Body,
+					Handler:     "handler." + binding.Handler,
+					Options:     options,
+				})
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(routes, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal routes.json: %w", err)
+	}
+	return string(data) + "\n", nil
+}
+
+// checkRoutes 检查所有服务的路由能否一起注册到Hertz的路由树，避免到服务启动时才panic
+// Hertz按HTTP方法分树且参数名不参与匹配，因此 /users/:id 与 /users/:name 冲突；
+// 静态段与参数段可以并存并优先匹配静态段，如 /users/:id 与 /users/new
+// 带 :verb 后缀的绑定与同形状的绑定分组，记录到 httpPkg.RouteGroups。
+// 各服务的handler生成在同一个包中，handler名相同的方法会互相覆盖，同样报告
+func checkRoutes(httpPkg *HTTPPackage) error {
+	type route struct {
+		method  *HTTPMethod
+		binding *Binding
+	}
+	seen := map[string]route{}
+	handlers := map[string]*HTTPMethod{}
+	groups := map[string]*RouteGroup{}
+	var keys []string // 包含带动词绑定的分组
+	index := 0
+	for _, service := range httpPkg.Services {
+		for _, method := range service.Methods {
+			for _, binding := range method.Bindings {
+				if other, ok := handlers[binding.Handler]; ok && other != method {
+					return fmt.Errorf("methods %s and %s both generate handler %s, rename one of them",
+						other.ProtoName, method.ProtoName, binding.Handler)
+				}
+				handlers[binding.Handler] = method
+				if err := checkRoutePath(binding.Path); err != nil {
+					return fmt.Errorf("method %s: route %s %s: %v", method.ProtoName, binding.HTTPMethod, binding.Path, err)
+				}
+				key := binding.HTTPMethod + " " + routeShape(binding.Path)
+				member := &RouteMember{Service: service, Method: method, Binding: binding, Index: index}
+				index++
+				group := groups[key]
+				if group == nil {
+					group = &RouteGroup{HTTPMethod: binding.HTTPMethod}
+					groups[key] = group
+				}
+				if binding.Verb != "" {
+					if !group.hasVerb() {
+						keys = append(keys, key)
+					}
+					group.Members = append(group.Members, member)
+					continue
+				}
+
+				other, ok := seen[key]
+				switch {
+				case !ok:
+					seen[key] = route{method, binding}
+					group.Members = append(group.Members, member)
+				case other.method == method:
+					return fmt.Errorf("method %s: bindings %s %s and %s %s register the same route",
+						method.ProtoName, other.binding.HTTPMethod, other.binding.Template, binding.HTTPMethod, binding.Template)
+				case other.binding.Path == binding.Path:
+					return fmt.Errorf("methods %s and %s both register %s %s",
+						other.method.ProtoName, method.ProtoName, binding.HTTPMethod, binding.Path)
+				default:
+					return fmt.Errorf("methods %s (%s %s) and %s (%s %s) conflict: the paths differ only in parameter names",
+						other.method.ProtoName, other.binding.HTTPMethod, other.binding.Path,
+						method.ProtoName, binding.HTTPMethod, binding.Path)
+				}
+			}
+		}
+	}
+
+	// 只有包含带动词绑定的分组需要分发路由
+	httpPkg.RouteGroups = nil
+	for _, key := range keys {
+		group := groups[key]
+		if err := groupRoutes(group); err != nil {
+			return err
+		}
+		httpPkg.RouteGroups = append(httpPkg.RouteGroups, group)
+	}
+	return nil
+}
+
+// groupRoutes 确定分组注册的路径并检查动词冲突：成员的路径只能在代替字面量的参数名上不同，
+// 同一字面量（或变量）上的动词只能出现一次
+func groupRoutes(group *RouteGroup) error {
+	lead := group.Members[0]
+	for _, member := range group.Members {
+		if member.Binding.VerbLiteral == "" {
+			lead = member
+			break
+		}
+	}
+	group.Path = lead.Binding.Path
+	group.Param = group.Path[strings.LastIndexAny(group.Path, ":*")+1:]
+
+	verbs := map[string]*RouteMember{}
+	for _, member := range group.Members {
+		binding := member.Binding
+		if !samePath(group.Path, binding.Path, binding.VerbLiteral != "") {
+			return fmt.Errorf("methods %s (%s %s) and %s (%s %s) conflict: the paths differ only in parameter names",
+				lead.Method.ProtoName, lead.Binding.HTTPMethod, lead.Binding.Template,
+				member.Method.ProtoName, binding.HTTPMethod, binding.Template)
+		}
+		if binding.Verb != "" {
+			key := binding.VerbLiteral + ":" + binding.Verb
+			if other, ok := verbs[key]; ok {
+				return fmt.Errorf("methods %s and %s both register %s %s",
+					other.Method.ProtoName, member.Method.ProtoName, binding.HTTPMethod, binding.Template)
+			}
+			verbs[key] = member
+		}
+		binding.Path = group.Path
+		binding.Group = group
+	}
+	group.Members[0].Binding.Dispatch = true
+	return nil
+}
+
+func (g *RouteGroup) hasVerb() bool {
+	for _, member := range g.Members {
+		if member.Binding.Verb != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// samePath 两个形状相同的路径的参数名是否一致，anyLast 时 b 的末段参数不绑定字段，名称不限
+func samePath(a, b string, anyLast bool) bool {
+	if anyLast {
+		a, b = a[:strings.LastIndex(a, "/")], b[:strings.LastIndex(b, "/")]
+	}
+	return a == b
+}
+
+// checkRoutePath 按Hertz注册路由时的规则检查路径
+func checkRoutePath(p string) error {
+	if !strings.HasPrefix(p, "/") {
+		return fmt.Errorf("path must begin with '/'")
+	}
+	segments := strings.Split(p[1:], "/")
+	for i, segment := range segments {
+		j := strings.IndexAny(segment, ":*")
+		if j < 0 {
+			continue
+		}
+		name := segment[j+1:]
+		switch {
+		case segment[j] == '*' && j > 0:
+			return fmt.Errorf("catch-all %q must start a path segment", segment[j:])
+		case segment[j] == '*' && i != len(segments)-1:
+			return fmt.Errorf("catch-all %q is only allowed at the end of the path", segment)
+		case name == "":
+			return fmt.Errorf("wildcard in segment %q has no name", segment)
+		case strings.ContainsAny(name, ":*"):
+			return fmt.Errorf("segment %q has more than one wildcard", segment)
+		}
+	}
+	return nil
+}
+
+// routeShape 去掉参数名后的路径，Hertz路由树中形状相同的路径匹配同一节点
+func routeShape(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		if j := strings.IndexAny(segment, ":*"); j >= 0 {
+			segments[i] = segment[:j+1]
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,352 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package generator
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testRoute 测试用的一个绑定，method 为proto方法全名，同一方法的多个绑定按出现顺序编号
+type testRoute struct {
+	method  string
+	verb    string // HTTP方法
+	path    string // Hertz路径
+	custom  string // 路径模板末尾的 :verb
+	literal string // 动词前被参数代替的字面量
+}
+
+// newTestPackage 按方法全名的服务部分把绑定归入服务与方法
+func newTestPackage(routes ...testRoute) *HTTPPackage {
+	httpPkg := &HTTPPackage{}
+	services := map[string]*Service{}
+	methods := map[string]*HTTPMethod{}
+	for _, r := range routes {
+		serviceName := r.method[:strings.LastIndex(r.method, ".")]
+		service := services[serviceName]
+		if service == nil {
+			service = &Service{Name: serviceName[strings.LastIndex(serviceName, ".")+1:]}
+			services[serviceName] = service
+			httpPkg.Services = append(httpPkg.Services, service)
+		}
+		method := methods[r.method]
+		if method == nil {
+			method = &HTTPMethod{Name: r.method[strings.LastIndex(r.method, ".")+1:], ProtoName: r.method}
+			methods[r.method] = method
+			service.Methods = append(service.Methods, method)
+		}
+		method.Bindings = append(method.Bindings, newTestBinding(method, r))
+	}
+	return httpPkg
+}
+
+func newTestBinding(method *HTTPMethod, r testRoute) *Binding {
+	handler := method.Name
+	if n := len(method.Bindings); n > 0 {
+		handler += "Binding" + strconv.Itoa(n)
+	}
+	template := r.path
+	if r.literal != "" {
+		template = template[:strings.LastIndex(template, "/")+1] + r.literal
+	}
+	if r.custom != "" {
+		template += ":" + r.custom
+	}
+	return &Binding{
+		HTTPMethod:  r.verb,
+		Path:        r.path,
+		Template:    template,
+		Handler:     handler,
+		Verb:        r.custom,
+		VerbLiteral: r.literal,
+	}
+}
+
+func TestCheckRoutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes []testRoute
+		err    []string // 期望错误包含的内容，为空时期望成功
+		groups []string // 期望的分组，格式为 "<HTTP方法> <路径> <末段参数> <成员数>"
+	}{
+		{
+			name: "distinct routes",
+			routes: []testRoute{
+				{method: "a.A.Get", verb: "GET", path: "/v1/users/:id"},
+				{method: "a.A.List", verb: "GET", path: "/v1/users"},
+				{method: "a.A.Update", verb: "PUT", path: "/v1/users/:id"},
+			},
+		},
+		{
+			name: "static segment beside parameter",
+			routes: []testRoute{
+				{method: "a.A.Get", verb: "GET", path: "/v1/users/:id"},
+				{method: "a.A.New", verb: "GET", path: "/v1/users/new"},
+			},
+		},
+		{
+			name: "same method and path",
+			routes: []testRoute{
+				{method: "a.A.Get", verb: "GET", path: "/v1/users/:id"},
+				{method: "a.A.Find", verb: "GET", path: "/v1/users/:id"},
+			},
+			err: []string{"methods a.A.Get and a.A.Find both register GET /v1/users/:id"},
+		},
+		{
+			name: "same path across services",
+			routes: []testRoute{
+				{method: "a.A.Get", verb: "GET", path: "/v1/users/:id"},
+				{method: "b.B.Find", verb: "GET", path: "/v1/users/:id"},
+			},
+			err: []string{"a.A.Get", "b.B.Find", "both register GET /v1/users/:id"},
+		},
+		{
+			name: "bindings of one method",
+			routes: []testRoute{
+				{method: "a.A.Get", verb: "GET", path: "/v1/users/:id"},
+				{method: "a.A.Get", verb: "GET", path: "/v1/users/:name"},
+			},
+			err: []string{"method a.A.Get: bindings GET /v1/users/:id and GET /v1/users/:name register the same route"},
+		},
+		{
+			name: "parameter names differ",
+			routes: []testRoute{
+				{method: "a.A.Get", verb: "GET", path: "/v1/users/:id"},
+				{method: "a.A.Find", verb: "GET", path: "/v1/users/:name"},
+			},
+			err: []string{"a.A.Get", "a.A.Find", "differ only in parameter names"},
+		},
+		{
+			name: "duplicate handler name",
+			routes: []testRoute{
+				{method: "s1.S1.Get", verb: "GET", path: "/v1/users/:id"},
+				{method: "s2.S2.Get", verb: "GET", path: "/v1/groups/:name"},
+			},
+			err: []string{"methods s1.S1.Get and s2.S2.Get both generate handler Get"},
+		},
+		{
+			name: "handler name of additional binding",
+			routes: []testRoute{
+				{method: "a.A.Get", verb: "GET", path: "/v1/users/:id"},
+				{method: "a.A.Get", verb: "GET", path: "/v2/users/:id"},
+				{method: "b.B.GetBinding1", verb: "GET", path: "/v1/groups/:id"},
+			},
+			err: []string{"methods a.A.Get and b.B.GetBinding1 both generate handler GetBinding1"},
+		},
+		{
+			name: "verbs grouped",
+			routes: []testRoute{
+				{method: "a.A.Get", verb: "POST", path: "/v1/books/:name"},
+				{method: "a.A.Archive", verb: "POST", path: "/v1/books/:name", custom: "archive"},
+				{method: "a.A.Restore", verb: "POST", path: "/v1/books/:name", custom: "restore"},
+				{method: "a.A.List", verb: "GET", path: "/v1/books/:name"},
+			},
+			groups: []string{"POST /v1/books/:name name 3"},
+		},
+		{
+			name: "verb after literal",
+			routes: []testRoute{
+				{method: "a.A.BatchGet", verb: "POST", path: "/v1/:_segment", custom: "batchGet", literal: "books"},
+				{method: "a.A.Create", verb: "POST", path: "/v1/:parent", custom: "create"},
+			},
+			groups: []string{"POST /v1/:parent parent 2"},
+		},
+		{
+			name: "duplicate verb",
+			routes: []testRoute{
+				{method: "a.A.Archive", verb: "POST", path: "/v1/books/:name", custom: "archive"},
+				{method: "b.B.Archive2", verb: "POST", path: "/v1/books/:name", custom: "archive"},
+			},
+			err: []string{"methods a.A.Archive and b.B.Archive2 both register POST /v1/books/:name:archive"},
+		},
+		{
+			name: "verb parameter names differ",
+			routes: []testRoute{
+				{method: "a.A.Archive", verb: "POST", path: "/v1/books/:name", custom: "archive"},
+				{method: "a.A.Restore", verb: "POST", path: "/v1/books/:id", custom: "restore"},
+			},
+			err: []string{"a.A.Archive", "a.A.Restore", "differ only in parameter names"},
+		},
+		{
+			name: "misplaced catch-all",
+			routes: []testRoute{
+				{method: "a.A.Get", verb: "GET", path: "/v1/files/*path/meta"},
+			},
+			err: []string{"method a.A.Get: route GET /v1/files/*path/meta", "only allowed at the end"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpPkg := newTestPackage(tt.routes...)
+			err := checkRoutes(httpPkg)
+			if len(tt.err) > 0 {
+				if err == nil {
+					t.Fatalf("checkRoutes succeeded, want error containing %q", tt.err)
+				}
+				for _, want := range tt.err {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("error %q does not contain %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkRoutes: %v", err)
+			}
+			var groups []string
+			for _, group := range httpPkg.RouteGroups {
+				groups = append(groups, group.HTTPMethod+" "+group.Path+" "+group.Param+" "+strconv.Itoa(len(group.Members)))
+				if !group.Members[0].Binding.Dispatch {
+					t.Errorf("group %s %s: first member does not dispatch", group.HTTPMethod, group.Path)
+				}
+			}
+			if strings.Join(groups, "\n") != strings.Join(tt.groups, "\n") {
+				t.Errorf("groups = %q, want %q", groups, tt.groups)
+			}
+		})
+	}
+}
+
+func TestGroupRoutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes []testRoute
+		path   string // 期望的分组路径
+		param  string // 期望的末段参数
+		err    []string
+	}{
+		{
+			name: "lead is the binding without literal",
+			routes: []testRoute{
+				{method: "a.A.BatchGet", verb: "POST", path: "/v1/:_segment", custom: "batchGet", literal: "books"},
+				{method: "a.A.Create", verb: "POST", path: "/v1/:parent", custom: "create"},
+			},
+			path:  "/v1/:parent",
+			param: "parent",
+		},
+		{
+			name: "literals only",
+			routes: []testRoute{
+				{method: "a.A.BatchGet", verb: "POST", path: "/v1/:_segment", custom: "batchGet", literal: "books"},
+				{method: "a.A.BatchDelete", verb: "POST", path: "/v1/:_segment", custom: "batchDelete", literal: "books"},
+			},
+			path:  "/v1/:_segment",
+			param: "_segment",
+		},
+		{
+			name: "catch-all",
+			routes: []testRoute{
+				{method: "a.A.Read", verb: "GET", path: "/v1/files/*path"},
+				{method: "a.A.Stat", verb: "GET", path: "/v1/files/*path", custom: "stat"},
+			},
+			path:  "/v1/files/*path",
+			param: "path",
+		},
+		{
+			name: "same verb on different literals",
+			routes: []testRoute{
+				{method: "a.A.BatchGet", verb: "POST", path: "/v1/:_segment", custom: "batchGet", literal: "books"},
+				{method: "a.A.BatchGetShelves", verb: "POST", path: "/v1/:_segment", custom: "batchGet", literal: "shelves"},
+			},
+			path:  "/v1/:_segment",
+			param: "_segment",
+		},
+		{
+			name: "same verb on one literal",
+			routes: []testRoute{
+				{method: "a.A.BatchGet", verb: "POST", path: "/v1/:_segment", custom: "batchGet", literal: "books"},
+				{method: "b.B.BatchGet2", verb: "POST", path: "/v1/:_segment", custom: "batchGet", literal: "books"},
+			},
+			err: []string{"methods a.A.BatchGet and b.B.BatchGet2 both register POST /v1/books:batchGet"},
+		},
+		{
+			name: "earlier parameter names differ",
+			routes: []testRoute{
+				{method: "a.A.Archive", verb: "POST", path: "/v1/:shelf/:_segment", custom: "archive", literal: "books"},
+				{method: "a.A.Restore", verb: "POST", path: "/v1/:id/:name", custom: "restore"},
+			},
+			err: []string{"methods a.A.Restore (POST /v1/:id/:name:restore) and a.A.Archive (POST /v1/:shelf/books:archive)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := &RouteGroup{HTTPMethod: tt.routes[0].verb}
+			for i, service := range newTestPackage(tt.routes...).Services {
+				for _, method := range service.Methods {
+					for _, binding := range method.Bindings {
+						group.Members = append(group.Members, &RouteMember{Service: service, Method: method, Binding: binding, Index: i})
+					}
+				}
+			}
+			err := groupRoutes(group)
+			if len(tt.err) > 0 {
+				if err == nil {
+					t.Fatalf("groupRoutes succeeded, want error containing %q", tt.err)
+				}
+				for _, want := range tt.err {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("error %q does not contain %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("groupRoutes: %v", err)
+			}
+			if group.Path != tt.path || group.Param != tt.param {
+				t.Errorf("group path %q param %q, want %q %q", group.Path, group.Param, tt.path, tt.param)
+			}
+			for i, member := range group.Members {
+				if member.Binding.Path != tt.path || member.Binding.Group != group || member.Binding.Dispatch != (i == 0) {
+					t.Errorf("member %d: path %q group %v dispatch %v", i, member.Binding.Path, member.Binding.Group == group, member.Binding.Dispatch)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckRoutePath(t *testing.T) {
+	tests := []struct {
+		path string
+		err  string // 期望错误包含的内容，为空时期望成功
+	}{
+		{path: "/"},
+		{path: "/v1/users/:id"},
+		{path: "/v1/files/*path"},
+		{path: "/v1/:shelf/books/:book"},
+		{path: "v1/users", err: "path must begin with '/'"},
+		{path: "/v1/files/*path/meta", err: `catch-all "*path" is only allowed at the end of the path`},
+		{path: "/v1/files/x*path", err: `catch-all "*path" must start a path segment`},
+		{path: "/v1/users/:", err: `wildcard in segment ":" has no name`},
+		{path: "/v1/files/*", err: `wildcard in segment "*" has no name`},
+		{path: "/v1/users/:id:name", err: `segment ":id:name" has more than one wildcard`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := checkRoutePath(tt.path)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("checkRoutePath(%q): %v", tt.path, err)
+			case tt.err != "" && err == nil:
+				t.Errorf("checkRoutePath(%q) succeeded, want error containing %q", tt.path, tt.err)
+			case tt.err != "" && !strings.Contains(err.Error(), tt.err):
+				t.Errorf("checkRoutePath(%q) = %q, want error containing %q", tt.path, err, tt.err)
+			}
+		})
+	}
+}
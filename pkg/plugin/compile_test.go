@@ -0,0 +1,55 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+// typeCheck 用go/types检查生成的包能否编译，与verify=true使用相同的检查
+// pkgs: import path -> 文件名 -> 内容；其余依赖从本模块的导出数据加载
+func typeCheck(t *testing.T, pkgs map[string]map[string]string) {
+	t.Helper()
+	fset := token.NewFileSet()
+	parsed := map[string][]*ast.File{}
+	for importPath, files := range pkgs {
+		for _, name := range sortedKeys(files) {
+			f, err := parser.ParseFile(fset, importPath+"/"+name, files[name], parser.SkipObjectResolution)
+			if err != nil {
+				t.Errorf("parse: %v", err)
+				return
+			}
+			pkg := importPath
+			if strings.HasSuffix(f.Name.Name, "_test") {
+				pkg += "_test"
+			}
+			parsed[pkg] = append(parsed[pkg], f)
+		}
+	}
+
+	errs, err := checkPackages("", fset, parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range errs {
+		t.Errorf("type check: %v", e)
+	}
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/ca-x/protoc-gen-go-hz/pkg/generator"
+)
+
+// DryRun 是否为dry_run模式，此时插件不写入任何文件，也不在响应中返回文件
+func (p *HZPlugin) DryRun() bool {
+	return p.args != nil && p.args.DryRun
+}
+
+// AddModelFiles 添加由插件之外生成的模型文件（命令行模式），使其出现在dry_run的差异报告中
+func (p *HZPlugin) AddModelFiles(files []*generator.GeneratedFile) {
+	p.modelFiles = append(p.modelFiles, files...)
+}
+
+// stage dry_run模式下记录本应写入的文件，路径相对于out_dir
+func (p *HZPlugin) stage(path, content string) {
+	p.staged = append(p.staged, &generator.GeneratedFile{Path: path, Content: content})
+}
+
+// writeDryRunReport 输出所有记录的文件与out_dir中现有文件的unified diff
+func (p *HZPlugin) writeDryRunReport() error {
+	files := append(append([]*generator.GeneratedFile{}, p.modelFiles...), p.staged...)
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	var report strings.Builder
+	var added, modified, unchanged int
+	for _, file := range files {
+		path := filepath.ToSlash(filepath.Clean(file.Path))
+		old, err := os.ReadFile(filepath.Join(p.args.OutDir, filepath.FromSlash(path)))
+		fromFile := "a/" + path
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			fromFile = "/dev/null"
+			added++
+		case err != nil:
+			return fmt.Errorf("dry run: %w", err)
+		case string(old) == file.Content:
+			unchanged++
+			continue
+		default:
+			modified++
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(string(old)),
+			B:        splitLines(file.Content),
+			FromFile: fromFile,
+			ToFile:   "b/" + path,
+			Context:  3,
+		})
+		if err != nil {
+			return fmt.Errorf("dry run: %w", err)
+		}
+		report.WriteString(diff)
+	}
+
+	summary := fmt.Sprintf("dry run: %d files would be added, %d modified, %d unchanged", added, modified, unchanged)
+	if p.args.DryRunReport == "" {
+		fmt.Fprint(os.Stderr, report.String())
+		fmt.Fprintln(os.Stderr, summary)
+		return nil
+	}
+	if err := os.WriteFile(p.args.DryRunReport, []byte(report.String()), 0o644); err != nil {
+		return fmt.Errorf("dry run: %w", err)
+	}
+	p.logger.Infof("%s, diff written to %s", summary, p.args.DryRunReport)
+	return nil
+}
+
+// splitLines 按行切分并保留换行符；difflib.SplitLines 会在末尾多出一个空行，生成的补丁无法应用
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
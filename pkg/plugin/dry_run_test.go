@@ -0,0 +1,201 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestDryRun(t *testing.T) {
+	tc := goldenCase{name: "dry_run", protoset: "greeter", files: []string{"greeter.proto"}, param: "client_dir=biz/client,routes_json=true"}
+	out := t.TempDir()
+	req := loadRequest(t, tc)
+	param := "out_dir=" + out + "," + tc.param
+	req.Parameter = &param
+
+	// 首次生成：布局与清单由插件写入out_dir，其余文件按protoc的方式写入
+	files, _ := runPlugin(t, req)
+	for name, content := range files {
+		writeFile(t, filepath.Join(out, filepath.FromSlash(name)), content)
+	}
+	// 之后的运行为update，只重新生成这些文件，布局与清单不变
+	total := len(files)
+
+	// 修改一个生成的文件，删除另一个
+	routerGo := filepath.Join(out, "biz", "router", "router.go")
+	b, err := os.ReadFile(routerGo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(routerGo, append(b, "\n// edited\n"...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(out, "biz", "client", "client.go")); err != nil {
+		t.Fatal(err)
+	}
+	before := listDir(t, out)
+
+	t.Run("report", func(t *testing.T) {
+		report := filepath.Join(t.TempDir(), "dry_run.diff")
+		dryParam := param + ",dry_run=true,dry_run_report=" + report
+		req.Parameter = &dryParam
+
+		gen := newGenerator(t, req)
+		p := NewHZPlugin(gen)
+		logger, hook := logtest.NewNullLogger()
+		p.logger = logger
+		if err := p.Run(); err != nil {
+			t.Fatal(err)
+		}
+		if got := responseFiles(t, gen); len(got) != 0 {
+			t.Errorf("dry run returned %d files to protoc", len(got))
+		}
+		if after := listDir(t, out); !equalDirs(before, after) {
+			t.Error("dry run changed out_dir")
+		}
+
+		var summary string
+		for _, e := range hook.AllEntries() {
+			if strings.HasPrefix(e.Message, "dry run:") {
+				summary = e.Message
+			}
+		}
+		want := "dry run: 1 files would be added, 1 modified, " + strconv.Itoa(total-2) + " unchanged"
+		if !strings.HasPrefix(summary, want) {
+			t.Errorf("summary = %q, want %q", summary, want)
+		}
+
+		diff := readDiff(t, report)
+		for _, header := range []string{
+			"--- /dev/null\n+++ b/biz/client/client.go\n",
+			"--- a/biz/router/router.go\n+++ b/biz/router/router.go\n",
+			"-// edited\n",
+		} {
+			if !strings.Contains(diff, header) {
+				t.Errorf("report lacks %q:\n%s", header, diff)
+			}
+		}
+		if n := strings.Count(diff, "\n+++ "); n != 2 {
+			t.Errorf("report has %d files, want 2:\n%s", n, diff)
+		}
+	})
+
+	t.Run("stderr", func(t *testing.T) {
+		dryParam := param + ",dry_run=true"
+		req.Parameter = &dryParam
+
+		stderr := captureStderr(t, func() {
+			gen := newGenerator(t, req)
+			p := NewHZPlugin(gen)
+			p.logger.SetOutput(io.Discard)
+			if err := p.Run(); err != nil {
+				t.Error(err)
+			}
+		})
+		want := "dry run: 1 files would be added, 1 modified, " + strconv.Itoa(total-2) + " unchanged\n"
+		if !strings.HasSuffix(stderr, want) {
+			t.Errorf("stderr ends with %q, want %q", lastLine(stderr), want)
+		}
+		if !strings.Contains(stderr, "+++ b/biz/client/client.go\n") {
+			t.Errorf("stderr lacks the diff of client.go:\n%s", stderr)
+		}
+	})
+
+	t.Run("report requires dry_run", func(t *testing.T) {
+		badParam := param + ",dry_run_report=" + filepath.Join(t.TempDir(), "x.diff")
+		req.Parameter = &badParam
+		p := NewHZPlugin(newGenerator(t, req))
+		p.logger.SetOutput(io.Discard)
+		if err := p.Run(); err == nil || !strings.Contains(err.Error(), "dry_run_report requires dry_run=true") {
+			t.Errorf("error = %v", err)
+		}
+	})
+}
+
+// listDir dir下全部文件的相对路径 -> 内容
+func listDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		files[filepath.ToSlash(rel)] = string(b)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func equalDirs(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, content := range a {
+		if c, ok := b[name]; !ok || c != content {
+			return false
+		}
+	}
+	return true
+}
+
+func readDiff(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// captureStderr 运行fn并返回其间写入os.Stderr的内容
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	f, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	saved := os.Stderr
+	os.Stderr = f
+	defer func() { os.Stderr = saved }()
+	fn()
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	return lines[len(lines)-1]
+}
@@ -0,0 +1,157 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ca-x/protoc-gen-go-hz/pkg/generator"
+	errorspb "github.com/ca-x/protoc-gen-go-hz/pkg/protobuf/errors"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultErrorStatus 错误枚举未指定 (errors.default_code) 时使用的HTTP状态码
+const defaultErrorStatus = 500
+
+// buildErrorEnums 收集带 (errors.default_code)/(errors.code) 注解的枚举
+// 生成的函数位于同一个errors包中，因此不同枚举的同名枚举值视为冲突
+func (p *HZPlugin) buildErrorEnums() ([]*generator.ErrorEnum, error) {
+	var enums []*generator.ErrorEnum
+	owners := make(map[string]string) // GoName -> 定义该值的枚举
+
+	var walk func(list []*protogen.Enum, messages []*protogen.Message) error
+	walk = func(list []*protogen.Enum, messages []*protogen.Message) error {
+		for _, enum := range list {
+			errEnum, err := buildErrorEnum(enum)
+			if err != nil {
+				return err
+			}
+			if errEnum == nil {
+				continue
+			}
+			for _, reason := range errEnum.Reasons {
+				if owner, ok := owners[reason.GoName]; ok {
+					return fmt.Errorf("error reason %s.%s conflicts with %s: both generate Is%s", errEnum.Name, reason.Name, owner, reason.GoName)
+				}
+				owners[reason.GoName] = errEnum.Name + "." + reason.Name
+			}
+			enums = append(enums, errEnum)
+		}
+		for _, msg := range messages {
+			if err := walk(msg.Enums, msg.Messages); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, file := range p.gen.Files {
+		if !file.Generate {
+			continue
+		}
+		if err := walk(file.Enums, file.Messages); err != nil {
+			return nil, err
+		}
+	}
+
+	return enums, nil
+}
+
+// buildErrorEnum 将单个枚举转换为错误枚举，未携带错误码注解时返回nil
+func buildErrorEnum(enum *protogen.Enum) (*generator.ErrorEnum, error) {
+	annotated := proto.HasExtension(enum.Desc.Options(), errorspb.E_DefaultCode)
+	defaultStatus := int(proto.GetExtension(enum.Desc.Options(), errorspb.E_DefaultCode).(int32))
+	if defaultStatus == 0 {
+		defaultStatus = defaultErrorStatus
+	}
+	if err := checkHTTPStatus(defaultStatus); err != nil {
+		return nil, fmt.Errorf("enum %s: (errors.default_code) %w", enum.Desc.FullName(), err)
+	}
+
+	errEnum := &generator.ErrorEnum{Name: string(enum.Desc.FullName())}
+	for _, value := range enum.Values {
+		status := defaultStatus
+		if proto.HasExtension(value.Desc.Options(), errorspb.E_Code) {
+			annotated = true
+			status = int(proto.GetExtension(value.Desc.Options(), errorspb.E_Code).(int32))
+			if err := checkHTTPStatus(status); err != nil {
+				return nil, fmt.Errorf("enum value %s: (errors.code) %w", value.Desc.FullName(), err)
+			}
+		}
+		goName := camelCase(string(value.Desc.Name()))
+		if err := checkReasonName(goName); err != nil {
+			return nil, fmt.Errorf("enum value %s: %w", value.Desc.FullName(), err)
+		}
+		errEnum.Reasons = append(errEnum.Reasons, &generator.ErrorReason{
+			Name:       string(value.Desc.Name()),
+			GoName:     goName,
+			HTTPStatus: status,
+		})
+	}
+
+	if !annotated {
+		return nil, nil
+	}
+	return errEnum, nil
+}
+
+// errorsPackageNames 生成的errors包（errorsTemplate）中的包级标识符，错误原因生成的函数不能与之重名
+var errorsPackageNames = map[string]bool{
+	"Code": true, "OK": true, "Canceled": true, "Unknown": true, "InvalidArgument": true,
+	"DeadlineExceeded": true, "NotFound": true, "AlreadyExists": true, "PermissionDenied": true,
+	"ResourceExhausted": true, "FailedPrecondition": true, "Aborted": true, "OutOfRange": true,
+	"Unimplemented": true, "Internal": true, "Unavailable": true, "DataLoss": true, "Unauthenticated": true,
+	"codeNames": true, "CodeFromHTTPStatus": true, "Error": true, "New": true, "Newf": true,
+	"NewReason": true, "IsReason": true, "FromStatus": true, "FromError": true, "CodeOf": true,
+	"FromHTTPResponse": true, "Encoder": true, "encoder": true, "SetEncoder": true, "codeKey": true,
+	"Encode": true, "EncodedCode": true, "DefaultEncoder": true,
+}
+
+// checkReasonName 校验错误原因生成的 Is<GoName>/Error<GoName> 是合法且不与errors包冲突的函数名
+func checkReasonName(goName string) error {
+	if goName == "" {
+		return fmt.Errorf("name has no letters or digits to generate Is<Name> and Error<Name> from")
+	}
+	for _, name := range []string{"Is" + goName, "Error" + goName} {
+		if errorsPackageNames[name] {
+			return fmt.Errorf("generates %s, which is already declared by the generated errors package", name)
+		}
+	}
+	return nil
+}
+
+// checkHTTPStatus 校验注解中的HTTP状态码
+func checkHTTPStatus(status int) error {
+	if status < 100 || status > 599 {
+		return fmt.Errorf("%d is not a valid HTTP status", status)
+	}
+	return nil
+}
+
+// camelCase 将枚举值名转换为驼峰形式，如 USER_NOT_FOUND -> UserNotFound
+func camelCase(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(strings.ToLower(name), "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
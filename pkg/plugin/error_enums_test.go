@@ -0,0 +1,142 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	errorspb "github.com/ca-x/protoc-gen-go-hz/pkg/protobuf/errors"
+)
+
+// errorEnumPlugin 以一个带 (errors.default_code) 的枚举 reasons.Reason 构造插件，枚举值按给定顺序编号
+func errorEnumPlugin(t *testing.T, values ...string) *HZPlugin {
+	t.Helper()
+	enumOpts := &descriptorpb.EnumOptions{}
+	proto.SetExtension(enumOpts, errorspb.E_DefaultCode, int32(404))
+	enum := &descriptorpb.EnumDescriptorProto{Name: proto.String("Reason"), Options: enumOpts}
+	for i, value := range values {
+		enum.Value = append(enum.Value, &descriptorpb.EnumValueDescriptorProto{Name: proto.String(value), Number: proto.Int32(int32(i))})
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("reasons.proto"),
+		Package:    proto.String("reasons"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{errorspb.File_errors_errors_proto.Path()},
+		Options:    &descriptorpb.FileOptions{GoPackage: proto.String("example.com/reasons/biz/model")},
+		EnumType:   []*descriptorpb.EnumDescriptorProto{enum},
+	}
+	gen, err := protogen.Options{}.New(&pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"reasons.proto"},
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			protodesc.ToFileDescriptorProto(descriptorpb.File_google_protobuf_descriptor_proto),
+			protodesc.ToFileDescriptorProto(errorspb.File_errors_errors_proto),
+			file,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &HZPlugin{gen: gen}
+}
+
+func TestBuildErrorEnums(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		names  []string // 期望的GoName
+		err    string   // 期望错误包含的内容，为空时期望成功
+	}{
+		{name: "names", values: []string{"REASON_UNSPECIFIED", "USER_NOT_FOUND", "NOT_FOUND", "ERROR", "CODE", "ENCODER"},
+			names: []string{"ReasonUnspecified", "UserNotFound", "NotFound", "Error", "Code", "Encoder"}},
+		{name: "IsReason", values: []string{"UNSPECIFIED", "REASON"},
+			err: "enum value reasons.REASON: generates IsReason, which is already declared by the generated errors package"},
+		{name: "lower case", values: []string{"UNSPECIFIED", "reason"},
+			err: "enum value reasons.reason: generates IsReason"},
+		{name: "underscores", values: []string{"UNSPECIFIED", "__"},
+			err: "enum value reasons.__: name has no letters or digits"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enums, err := errorEnumPlugin(t, tt.values...).buildErrorEnums()
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("buildErrorEnums() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildErrorEnums: %v", err)
+			}
+			var names []string
+			for _, reason := range enums[0].Reasons {
+				names = append(names, reason.GoName)
+			}
+			if strings.Join(names, " ") != strings.Join(tt.names, " ") {
+				t.Errorf("GoNames = %q, want %q", names, tt.names)
+			}
+		})
+	}
+}
+
+// TestErrorsPackageNames errorsPackageNames 与生成的errors.go中的包级标识符一致
+func TestErrorsPackageNames(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), filepath.Join("testdata", "golden", "users", "biz", "errors", "errors.go.golden"), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	declared := map[string]bool{}
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil {
+				declared[decl.Name.Name] = true
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					declared[spec.Name.Name] = true
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						declared[name.Name] = true
+					}
+				}
+			}
+		}
+	}
+	for name := range declared {
+		if !errorsPackageNames[name] {
+			t.Errorf("errors.go declares %s, missing from errorsPackageNames", name)
+		}
+	}
+	for name := range errorsPackageNames {
+		if !declared[name] {
+			t.Errorf("errorsPackageNames has %s, not declared in errors.go", name)
+		}
+	}
+}
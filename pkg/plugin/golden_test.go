@@ -0,0 +1,249 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"flag"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// 更新golden文件: go test ./pkg/plugin -run TestGolden -update
+var update = flag.Bool("update", false, "update golden files in testdata/golden")
+
+// goldenCase 一个golden测试用例：用testdata/<protoset>.protoset中的描述符
+// 构造CodeGeneratorRequest，以param运行插件，并与testdata/golden/<name>比较
+//
+// protoset由testdata/protos中的proto文件预编译而成，测试不依赖protoc：
+//
+//	protoc --include_imports --include_source_info -I testdata/protos -I ../protobuf \
+//	  --descriptor_set_out=testdata/<name>.protoset <name>.proto
+//
+// library.proto 还需要以 -I 指定 googleapis 的目录
+type goldenCase struct {
+	name     string
+	protoset string
+	files    []string
+	param    string
+}
+
+var goldenCases = []goldenCase{
+	{name: "greeter", protoset: "greeter", files: []string{"greeter.proto"}},
+	{name: "greeter_client", protoset: "greeter", files: []string{"greeter.proto"},
+		param: "client_dir=biz/client,proto_text=true,client_mock=true"},
+	{name: "users", protoset: "users", files: []string{"users.proto"},
+		param: "client_dir=biz/client,handler_test=true,client_mock=true,otel=true,routes_json=true"},
+	{name: "sse", protoset: "watch", files: []string{"watch.proto"},
+		param: "client_dir=biz/client,handler_test=true,client_mock=true,routes_json=true"},
+	{name: "websocket", protoset: "chat", files: []string{"chat.proto"},
+		param: "client_dir=biz/client,websocket=true,client_mock=true,otel=true"},
+	{name: "editions", protoset: "editions", files: []string{"editions.proto"},
+		param: "handler_test=true"},
+	{name: "library", protoset: "library", files: []string{"library.proto"},
+		param: "client_dir=biz/client,handler_test=true,client_mock=true,routes_json=true"},
+	{name: "wire", protoset: "wire", files: []string{"wire.proto"},
+		param: "client_dir=biz/client"},
+}
+
+func TestGolden(t *testing.T) {
+	for _, tc := range goldenCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := loadRequest(t, tc)
+			files, gomod := runPlugin(t, req)
+
+			compareGolden(t, filepath.Join("testdata", "golden", tc.name), files)
+
+			pkgs := map[string]map[string]string{}
+			for name, content := range files {
+				if !strings.HasSuffix(name, ".go") {
+					continue
+				}
+				addFile(pkgs, gomod+"/"+path.Dir(name), path.Base(name), content)
+			}
+			models, err := generateModels(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, content := range models {
+				addFile(pkgs, path.Dir(name), path.Base(name), content)
+			}
+			typeCheck(t, pkgs)
+		})
+	}
+}
+
+// loadRequest 由预编译的描述符集构造插件请求
+func loadRequest(t *testing.T, tc goldenCase) *pluginpb.CodeGeneratorRequest {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", tc.protoset+".protoset"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(b, set); err != nil {
+		t.Fatalf("unmarshal %s.protoset: %v", tc.protoset, err)
+	}
+
+	// out_dir指向临时目录，项目布局与清单写入其中，不影响golden输出
+	param := "out_dir=" + t.TempDir()
+	if tc.param != "" {
+		param += "," + tc.param
+	}
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: tc.files,
+		Parameter:      proto.String(param),
+		ProtoFile:      set.GetFile(),
+		CompilerVersion: &pluginpb.Version{
+			Major: proto.Int32(27),
+			Minor: proto.Int32(1),
+			Patch: proto.Int32(0),
+		},
+	}
+}
+
+// newGenerator 以与main相同的特性声明创建protogen插件
+func newGenerator(t *testing.T, req *pluginpb.CodeGeneratorRequest) *protogen.Plugin {
+	t.Helper()
+	gen, err := protogen.Options{}.New(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL |
+		pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS)
+	gen.SupportedEditionsMinimum = descriptorpb.Edition_EDITION_PROTO2
+	gen.SupportedEditionsMaximum = descriptorpb.Edition_EDITION_2023
+	return gen
+}
+
+// runPlugin 运行插件，返回响应中的文件与推导出的go module
+func runPlugin(t *testing.T, req *pluginpb.CodeGeneratorRequest) (map[string]string, string) {
+	t.Helper()
+	gen := newGenerator(t, req)
+	p := NewHZPlugin(gen)
+	p.logger.SetOutput(io.Discard)
+	if err := p.Run(); err != nil {
+		t.Fatalf("run plugin: %v", err)
+	}
+	return responseFiles(t, gen), p.args.Gomod
+}
+
+func responseFiles(t *testing.T, gen *protogen.Plugin) map[string]string {
+	t.Helper()
+	resp := gen.Response()
+	if resp.Error != nil {
+		t.Fatalf("plugin error: %s", resp.GetError())
+	}
+	files := make(map[string]string, len(resp.GetFile()))
+	for _, f := range resp.GetFile() {
+		files[f.GetName()] = f.GetContent()
+	}
+	return files
+}
+
+func addFile(pkgs map[string]map[string]string, importPath, name, content string) {
+	if pkgs[importPath] == nil {
+		pkgs[importPath] = map[string]string{}
+	}
+	pkgs[importPath][name] = content
+}
+
+// compareGolden 比较生成的文件与golden目录，-update时重写golden目录
+func compareGolden(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	if *update {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+		for name, content := range files {
+			golden := filepath.Join(dir, filepath.FromSlash(name)+".golden")
+			if err := os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(golden, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return
+	}
+
+	want := map[string]string{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		want[strings.TrimSuffix(filepath.ToSlash(rel), ".golden")] = string(b)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("read golden files: %v (run with -update to create them)", err)
+	}
+
+	for _, name := range sortedKeys(files) {
+		w, ok := want[name]
+		switch {
+		case !ok:
+			t.Errorf("%s: unexpected file, not in golden", name)
+		case w != files[name]:
+			t.Errorf("%s: differs from golden at line %d (run with -update to accept)",
+				name, firstDiffLine(w, files[name]))
+		}
+	}
+	for _, name := range sortedKeys(want) {
+		if _, ok := files[name]; !ok {
+			t.Errorf("%s: in golden but not generated", name)
+		}
+	}
+}
+
+// firstDiffLine 第一处不同所在的行号，从1开始
+func firstDiffLine(a, b string) int {
+	al, bl := strings.Split(a, "\n"), strings.Split(b, "\n")
+	for i := 0; i < len(al) && i < len(bl); i++ {
+		if al[i] != bl[i] {
+			return i + 1
+		}
+	}
+	if len(al) < len(bl) {
+		return len(al) + 1
+	}
+	return len(bl) + 1
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,402 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/ca-x/protoc-gen-go-hz/pkg/generator"
+)
+
+// methodBindings 方法的HTTP路由：google.api.http 的主规则及其 additional_bindings
+// 未声明时为 POST /<Service>/<Method>，请求体为整个请求
+func methodBindings(service *protogen.Service, method *protogen.Method) ([]*generator.Binding, error) {
+	name := string(method.GoName)
+	if !proto.HasExtension(method.Desc.Options(), annotations.E_Http) {
+		path := "/" + string(service.GoName) + "/" + name
+		return []*generator.Binding{{
+			HTTPMethod: "POST",
+			Path:       path,
+			Template:   path,
+			Body:       "*",
+			Handler:    name,
+			Segments:   []*generator.Segment{{Literal: path}},
+		}}, nil
+	}
+
+	rule := proto.GetExtension(method.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
+	rules := append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...)
+	bindings := make([]*generator.Binding, 0, len(rules))
+	for i, r := range rules {
+		if i > 0 && len(r.GetAdditionalBindings()) > 0 {
+			return nil, fmt.Errorf("method %s: additional_bindings cannot be nested", method.Desc.FullName())
+		}
+		binding, err := parseHTTPRule(method.Input, r)
+		if err != nil {
+			return nil, fmt.Errorf("method %s: %w", method.Desc.FullName(), err)
+		}
+		binding.Handler = name
+		if i > 0 {
+			binding.Handler = fmt.Sprintf("%sBinding%d", name, i)
+		}
+		bindings = append(bindings, binding)
+	}
+	return bindings, nil
+}
+
+// parseHTTPRule 将一条 google.api.http 规则转换为Hertz路由，并校验路径变量与请求体字段
+func parseHTTPRule(input *protogen.Message, rule *annotations.HttpRule) (*generator.Binding, error) {
+	var verb, template string
+	switch pattern := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		verb, template = "GET", pattern.Get
+	case *annotations.HttpRule_Put:
+		verb, template = "PUT", pattern.Put
+	case *annotations.HttpRule_Post:
+		verb, template = "POST", pattern.Post
+	case *annotations.HttpRule_Delete:
+		verb, template = "DELETE", pattern.Delete
+	case *annotations.HttpRule_Patch:
+		verb, template = "PATCH", pattern.Patch
+	case *annotations.HttpRule_Custom:
+		verb, template = pattern.Custom.GetKind(), pattern.Custom.GetPath()
+		if !isToken(verb) {
+			return nil, fmt.Errorf("custom HTTP method %q is not a valid method name", verb)
+		}
+	default:
+		return nil, fmt.Errorf("google.api.http rule has no HTTP method and path")
+	}
+	if rule.GetResponseBody() != "" {
+		return nil, fmt.Errorf("%s %s: response_body is not supported", verb, template)
+	}
+
+	binding := &generator.Binding{HTTPMethod: verb, Template: template, Body: rule.GetBody()}
+	if err := parsePathTemplate(binding, input); err != nil {
+		return nil, fmt.Errorf("%s %s: %w", verb, template, err)
+	}
+
+	switch binding.Body {
+	case "", "*":
+	default:
+		field := fieldByName(input, binding.Body)
+		if field == nil || field.Desc.Message() == nil || field.Desc.IsList() || field.Desc.IsMap() {
+			return nil, fmt.Errorf("%s %s: body %q is not a message field of %s",
+				verb, template, binding.Body, input.Desc.FullName())
+		}
+		for _, param := range binding.Params {
+			if param.Field == binding.Body || strings.HasPrefix(param.Field, binding.Body+".") {
+				return nil, fmt.Errorf("%s %s: field %s is bound to both the path and the body", verb, template, param.Field)
+			}
+		}
+		binding.BodyField = field.GoName
+	}
+	return binding, nil
+}
+
+// parsePathTemplate 解析路径模板，生成Hertz路径、路径变量与客户端路径片段
+// 变量 {field} 占一个路径段，{field=shelves/*} 等多段变量由字面量与多个Hertz参数拼接，
+// {field=**} 对应末尾的通配参数
+// 末尾的 :verb 不进入Hertz路径，由分发路由从末段参数的值中解析；末段为字面量时以参数代替
+func parsePathTemplate(binding *generator.Binding, input *protogen.Message) error {
+	template := binding.Template
+	if !strings.HasPrefix(template, "/") {
+		return fmt.Errorf("path must begin with '/'")
+	}
+	template, verb, ok := cutVerb(template)
+	if ok && (verb == "" || strings.ContainsAny(verb, ":*{}/")) {
+		return fmt.Errorf("invalid custom verb %q", verb)
+	}
+	binding.Verb = verb
+	segments, err := splitTemplate(template[1:])
+	if err != nil {
+		return err
+	}
+
+	var hertz []string
+	literal := "/"
+	bound := map[string]bool{}
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, "{") {
+			if segment == "*" || segment == "**" {
+				return fmt.Errorf("wildcard %q must be bound to a field, e.g. {name=%s}", segment, segment)
+			}
+			if strings.ContainsAny(segment, ":*{}") {
+				return fmt.Errorf("invalid literal segment %q", segment)
+			}
+			hertz = append(hertz, segment)
+			literal += segment
+			if i < len(segments)-1 {
+				literal += "/"
+			}
+			continue
+		}
+
+		fieldPath, pattern, ok := strings.Cut(segment[1:len(segment)-1], "=")
+		if !ok {
+			pattern = "*"
+		}
+		field, err := pathField(input, fieldPath)
+		if err != nil {
+			return err
+		}
+		if bound[fieldPath] {
+			return fmt.Errorf("field %s is bound more than once", fieldPath)
+		}
+		bound[fieldPath] = true
+
+		parts := strings.Split(pattern, "/")
+		multi := len(parts) > 1 || pattern == "**"
+		if multi && field.Desc.Kind() != protoreflect.StringKind {
+			return fmt.Errorf("field %s of multi-segment variable %s must be a string", fieldPath, segment)
+		}
+		wildcards := strings.Count(pattern, "*") - strings.Count(pattern, "**")
+		param := &generator.PathParam{Field: fieldPath}
+		n := 0
+		for j, part := range parts {
+			if j > 0 {
+				appendLiteral(param, "/")
+			}
+			switch part {
+			case "*", "**":
+				n++
+				name := fieldPath
+				if wildcards > 1 {
+					name = fmt.Sprintf("%s_%d", fieldPath, n)
+				}
+				if part == "**" {
+					if j != len(parts)-1 || i != len(segments)-1 {
+						return fmt.Errorf("** in %s is only allowed at the end of the path", segment)
+					}
+					hertz = append(hertz, "*"+name)
+				} else {
+					hertz = append(hertz, ":"+name)
+				}
+				param.Parts = append(param.Parts, &generator.PathPart{Param: name})
+			default:
+				if part == "" || strings.ContainsAny(part, ":*{}") {
+					return fmt.Errorf("invalid segment %q in variable %s", part, segment)
+				}
+				hertz = append(hertz, part)
+				appendLiteral(param, part)
+			}
+		}
+		binding.Params = append(binding.Params, param)
+
+		if literal != "" {
+			binding.Segments = append(binding.Segments, &generator.Segment{Literal: literal})
+		}
+		binding.Segments = append(binding.Segments, &generator.Segment{Field: fieldPath, Multi: multi})
+		literal = ""
+		if i < len(segments)-1 {
+			literal = "/"
+		}
+	}
+	if binding.Verb != "" {
+		last := hertz[len(hertz)-1]
+		if last == "" {
+			return fmt.Errorf("custom verb %q must follow a path segment", binding.Verb)
+		}
+		literal += ":" + binding.Verb
+		if last[0] != ':' && last[0] != '*' {
+			binding.VerbLiteral = last
+			hertz[len(hertz)-1] = ":" + verbSegmentParam
+		}
+	}
+	if literal != "" {
+		binding.Segments = append(binding.Segments, &generator.Segment{Literal: literal})
+	}
+	binding.Path = "/" + strings.Join(hertz, "/")
+	return nil
+}
+
+// verbSegmentParam 代替动词前字面量段的Hertz参数名，不绑定请求字段
+const verbSegmentParam = "_segment"
+
+// cutVerb 切下路径模板末尾的 :verb，冒号须位于末段且在变量之外
+func cutVerb(template string) (string, string, bool) {
+	start := strings.LastIndexAny(template, "/}")
+	if i := strings.LastIndexByte(template, ':'); i > start {
+		return template[:i], template[i+1:], true
+	}
+	return template, "", false
+}
+
+// isToken 是否为合法的HTTP方法名（RFC 9110 token）
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// splitTemplate 按 / 切分路径模板，变量内的 / 不切分
+func splitTemplate(template string) ([]string, error) {
+	var segments []string
+	depth, start := 0, 0
+	for i, r := range template {
+		switch r {
+		case '{':
+			if depth > 0 || i != start {
+				return nil, fmt.Errorf("variable must span a whole path segment")
+			}
+			depth++
+		case '}':
+			if depth == 0 {
+				return nil, fmt.Errorf("unbalanced '}'")
+			}
+			depth--
+			if i+1 < len(template) && template[i+1] != '/' {
+				return nil, fmt.Errorf("variable must span a whole path segment")
+			}
+		case '/':
+			if depth == 0 {
+				segments = append(segments, template[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced '{'")
+	}
+	segments = append(segments, template[start:])
+	return segments, nil
+}
+
+// appendLiteral 向路径变量追加字面量片段，与前一个字面量片段合并
+func appendLiteral(param *generator.PathParam, literal string) {
+	if n := len(param.Parts); n > 0 && param.Parts[n-1].Param == "" {
+		param.Parts[n-1].Literal += literal
+		return
+	}
+	param.Parts = append(param.Parts, &generator.PathPart{Literal: literal})
+}
+
+// pathField 路径变量绑定的字段：中间字段为非重复的消息字段，末尾字段为非重复的标量或枚举字段
+func pathField(input *protogen.Message, path string) (*protogen.Field, error) {
+	message := input
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		field := fieldByName(message, name)
+		if field == nil {
+			return nil, fmt.Errorf("field %s: %s has no field %s", path, message.Desc.FullName(), name)
+		}
+		if field.Desc.IsList() || field.Desc.IsMap() {
+			return nil, fmt.Errorf("field %s: repeated and map fields cannot be bound to the path", path)
+		}
+		if i == len(names)-1 {
+			if field.Message != nil {
+				return nil, fmt.Errorf("field %s: message fields cannot be bound to the path", path)
+			}
+			return field, nil
+		}
+		if field.Message == nil {
+			return nil, fmt.Errorf("field %s: %s is not a message field", path, name)
+		}
+		message = field.Message
+	}
+	return nil, fmt.Errorf("empty field path")
+}
+
+// fieldByName 按proto字段名查找字段
+func fieldByName(message *protogen.Message, name string) *protogen.Field {
+	for _, field := range message.Fields {
+		if string(field.Desc.Name()) == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// samplePath 将首个路由的路径变量替换为示例值后的请求路径，用于handler测试脚手架
+func samplePath(binding *generator.Binding, input *protogen.Message) string {
+	samples := map[string]string{}
+	for _, param := range binding.Params {
+		value := "sample"
+		if field, err := pathField(input, param.Field); err == nil && len(param.Parts) == 1 {
+			value = samplePathValue(field)
+		}
+		for _, part := range param.Parts {
+			if part.Param != "" {
+				samples[part.Param] = value
+			}
+		}
+	}
+
+	segments := strings.Split(binding.Path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = samples[segment[1:]]
+		}
+	}
+	if binding.VerbLiteral != "" {
+		segments[len(segments)-1] = binding.VerbLiteral
+	}
+	path := strings.Join(segments, "/")
+	if binding.Verb != "" {
+		path += ":" + binding.Verb
+	}
+	return path
+}
+
+// samplePathValue 路径变量的示例文本，需能被生成的handler解析
+func samplePathValue(field *protogen.Field) string {
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		return "true"
+	case protoreflect.StringKind:
+		return "sample"
+	case protoreflect.BytesKind:
+		return "c2FtcGxl"
+	case protoreflect.EnumKind:
+		values := field.Desc.Enum().Values()
+		if values.Len() > 1 {
+			return string(values.Get(1).Name())
+		}
+		return string(values.Get(0).Name())
+	}
+	return "1"
+}
+
+// websocketBindings 校验WebSocket方法的路由：握手为没有请求体的GET，路径中不能有变量
+// 未声明 google.api.http 的方法改用 GET
+func websocketBindings(method *protogen.Method, bindings []*generator.Binding) error {
+	if !proto.HasExtension(method.Desc.Options(), annotations.E_Http) {
+		bindings[0].HTTPMethod, bindings[0].Body = "GET", ""
+		return nil
+	}
+	for _, binding := range bindings {
+		if binding.HTTPMethod != "GET" || binding.Body != "" || len(binding.Params) > 0 {
+			return fmt.Errorf("method %s: %s %s: WebSocket streams are opened with a GET request without body or path variables",
+				method.Desc.FullName(), binding.HTTPMethod, binding.Template)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+// libraryMessages library.proto 中的消息，按Go名索引
+func libraryMessages(t *testing.T) map[string]*protogen.Message {
+	t.Helper()
+	gen := newGenerator(t, loadRequest(t, goldenCase{name: "library", protoset: "library", files: []string{"library.proto"}}))
+	messages := map[string]*protogen.Message{}
+	for _, file := range gen.Files {
+		for _, message := range file.Messages {
+			messages[message.GoIdent.GoName] = message
+		}
+	}
+	return messages
+}
+
+func TestParseHTTPRule(t *testing.T) {
+	get := func(path string) *annotations.HttpRule {
+		return &annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: path}}
+	}
+	post := func(path, body string) *annotations.HttpRule {
+		return &annotations.HttpRule{Pattern: &annotations.HttpRule_Post{Post: path}, Body: body}
+	}
+	tests := []struct {
+		name    string
+		input   string
+		rule    *annotations.HttpRule
+		path    string // 期望的Hertz路径
+		literal string // 期望的 VerbLiteral
+		err     string // 期望错误包含的内容，为空时期望成功
+	}{
+		{name: "field", input: "GetBookRequest", rule: get("/v1/shelves/{shelf}/books/{id}"), path: "/v1/shelves/:shelf/books/:id"},
+		{name: "multi-segment", input: "UpdateBookRequest", rule: &annotations.HttpRule{
+			Pattern: &annotations.HttpRule_Patch{Patch: "/v1/{book.name=shelves/*/books/*}"}, Body: "*"},
+			path: "/v1/shelves/:book.name_1/books/:book.name_2"},
+		{name: "rest of path", input: "GetFileRequest", rule: get("/v1/files/{path=**}"), path: "/v1/files/*path"},
+		{name: "verb after variable", input: "ArchiveBookRequest", rule: post("/v1/{name=books/*}:archive", "*"), path: "/v1/books/:name"},
+		{name: "verb after literal", input: "BatchGetBooksRequest", rule: get("/v1/books:batchGet"), path: "/v1/:_segment", literal: "books"},
+		{name: "custom method", input: "GetBookRequest", rule: &annotations.HttpRule{
+			Pattern: &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{Kind: "SEARCH", Path: "/v1/books/{id}"}}},
+			path: "/v1/books/:id"},
+
+		{name: "no pattern", input: "GetBookRequest", rule: &annotations.HttpRule{},
+			err: "google.api.http rule has no HTTP method and path"},
+		{name: "invalid custom method", input: "GetBookRequest", rule: &annotations.HttpRule{
+			Pattern: &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{Kind: "GET BOOK", Path: "/v1/books"}}},
+			err: `custom HTTP method "GET BOOK" is not a valid method name`},
+		{name: "empty custom method", input: "GetBookRequest", rule: &annotations.HttpRule{
+			Pattern: &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{Path: "/v1/books"}}},
+			err: `custom HTTP method "" is not a valid method name`},
+		{name: "response body", input: "GetBookRequest", rule: &annotations.HttpRule{
+			Pattern: &annotations.HttpRule_Get{Get: "/v1/books/{id}"}, ResponseBody: "book"},
+			err: "GET /v1/books/{id}: response_body is not supported"},
+		{name: "relative path", input: "GetBookRequest", rule: get("v1/books/{id}"),
+			err: "GET v1/books/{id}: path must begin with '/'"},
+		{name: "empty verb", input: "GetBookRequest", rule: get("/v1/books/{id}:"),
+			err: `invalid custom verb ""`},
+		{name: "invalid verb", input: "ArchiveBookRequest", rule: post("/v1/{name=books/*}:arch*ive", "*"),
+			err: `invalid custom verb "arch*ive"`},
+		{name: "verb without segment", input: "GetBookRequest", rule: get("/v1/:batchGet"),
+			err: `custom verb "batchGet" must follow a path segment`},
+		{name: "unbound wildcard", input: "GetBookRequest", rule: get("/v1/*/books/{id}"),
+			err: `wildcard "*" must be bound to a field, e.g. {name=*}`},
+		{name: "unbound double wildcard", input: "GetFileRequest", rule: get("/v1/files/**"),
+			err: `wildcard "**" must be bound to a field, e.g. {name=**}`},
+		{name: "invalid literal", input: "GetBookRequest", rule: get("/v1/bo*oks/{id}"),
+			err: `invalid literal segment "bo*oks"`},
+		{name: "variable inside segment", input: "GetBookRequest", rule: get("/v1/books-{id}"),
+			err: "variable must span a whole path segment"},
+		{name: "text after variable", input: "GetBookRequest", rule: get("/v1/books/{id}.json"),
+			err: "variable must span a whole path segment"},
+		{name: "nested variable", input: "GetBookRequest", rule: get("/v1/books/{id={shelf}}"),
+			err: "variable must span a whole path segment"},
+		{name: "unbalanced close", input: "GetBookRequest", rule: get("/v1/books/id}"),
+			err: "unbalanced '}'"},
+		{name: "unbalanced open", input: "GetBookRequest", rule: get("/v1/books/{id"),
+			err: "unbalanced '{'"},
+		{name: "unknown field", input: "GetBookRequest", rule: get("/v1/books/{isbn}"),
+			err: "field isbn: library.v1.GetBookRequest has no field isbn"},
+		{name: "unknown nested field", input: "UpdateBookRequest", rule: get("/v1/books/{book.isbn}"),
+			err: "field book.isbn: library.v1.Book has no field isbn"},
+		{name: "repeated field", input: "BatchGetBooksRequest", rule: get("/v1/books/{ids}"),
+			err: "field ids: repeated and map fields cannot be bound to the path"},
+		{name: "message field", input: "UpdateBookRequest", rule: get("/v1/books/{book}"),
+			err: "field book: message fields cannot be bound to the path"},
+		{name: "path through scalar", input: "GetBookRequest", rule: get("/v1/books/{shelf.name}"),
+			err: "field shelf.name: shelf is not a message field"},
+		{name: "field bound twice", input: "GetBookRequest", rule: get("/v1/shelves/{id}/books/{id}"),
+			err: "field id is bound more than once"},
+		{name: "multi-segment non-string", input: "GetBookRequest", rule: get("/v1/{id=books/*}"),
+			err: "field id of multi-segment variable {id=books/*} must be a string"},
+		{name: "double wildcard not at end", input: "GetFileRequest", rule: get("/v1/{path=**}/content"),
+			err: "** in {path=**} is only allowed at the end of the path"},
+		{name: "double wildcard inside variable", input: "GetFileRequest", rule: get("/v1/{path=**/x}"),
+			err: "** in {path=**/x} is only allowed at the end of the path"},
+		{name: "empty variable segment", input: "ArchiveBookRequest", rule: get("/v1/{name=books//*}"),
+			err: `invalid segment "" in variable {name=books//*}`},
+		{name: "invalid variable segment", input: "ArchiveBookRequest", rule: get("/v1/{name=books/*x}"),
+			err: `invalid segment "*x" in variable {name=books/*x}`},
+		{name: "body not a field", input: "CreateBookRequest", rule: post("/v1/shelves/{shelf}/books", "volume"),
+			err: `POST /v1/shelves/{shelf}/books: body "volume" is not a message field of library.v1.CreateBookRequest`},
+		{name: "body scalar", input: "CreateBookRequest", rule: post("/v1/shelves/{shelf}/books", "request_id"),
+			err: `body "request_id" is not a message field`},
+		{name: "body repeated", input: "BatchGetBooksRequest", rule: post("/v1/books", "ids"),
+			err: `body "ids" is not a message field`},
+		{name: "path and body", input: "UpdateBookRequest", rule: &annotations.HttpRule{
+			Pattern: &annotations.HttpRule_Patch{Patch: "/v1/{book.name=books/*}"}, Body: "book"},
+			err: "PATCH /v1/{book.name=books/*}: field book.name is bound to both the path and the body"},
+	}
+	messages := libraryMessages(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := messages[tt.input]
+			if input == nil {
+				t.Fatalf("no message %s in library.proto", tt.input)
+			}
+			binding, err := parseHTTPRule(input, tt.rule)
+			if tt.err != "" {
+				if err == nil {
+					t.Fatalf("parseHTTPRule succeeded with path %q, want error containing %q", binding.Path, tt.err)
+				}
+				if !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("parseHTTPRule() = %q, want error containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseHTTPRule: %v", err)
+			}
+			if binding.Path != tt.path || binding.VerbLiteral != tt.literal {
+				t.Errorf("path %q verb literal %q, want %q %q", binding.Path, binding.VerbLiteral, tt.path, tt.literal)
+			}
+		})
+	}
+}
@@ -18,17 +18,23 @@ package plugin
 
 import (
 	"fmt"
+	"go/format"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ca-x/protoc-gen-go-hz/pkg/config"
 	"github.com/ca-x/protoc-gen-go-hz/pkg/generator"
+	hzpb "github.com/ca-x/protoc-gen-go-hz/pkg/protobuf/hz"
 	"github.com/cloudwego/hertz/cmd/hz/generator/model"
 	"github.com/cloudwego/hertz/cmd/hz/meta"
 	"github.com/cloudwego/hertz/cmd/hz/util/logs"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 // HZPlugin 是HZ protoc插件的主体
@@ -36,6 +42,10 @@ type HZPlugin struct {
 	gen    *protogen.Plugin
 	args   *config.Argument
 	logger *logrus.Logger
+
+	// dry_run模式下记录的文件，最后与out_dir中的文件比较
+	staged     []*generator.GeneratedFile
+	modelFiles []*generator.GeneratedFile
 }
 
 // NewHZPlugin 创建新的HZ插件实例
@@ -47,7 +57,7 @@ func NewHZPlugin(gen *protogen.Plugin) *HZPlugin {
 }
 
 // Run 运行插件
-func (p *HZPlugin) Run() error {
+func (p *HZPlugin) Run() (err error) {
 	// 解析插件参数
 	if err := p.parseArgs(); err != nil {
 		return fmt.Errorf("parse args failed: %w", err)
@@ -61,30 +71,94 @@ func (p *HZPlugin) Run() error {
 
 	p.logger.Debug("HZ protoc plugin started")
 
+	// 非严格模式下的参数问题仅在verbose模式下提示
+	if p.args.Verbose {
+		for _, w := range p.args.Warnings {
+			p.logger.Warnf("%s (use strict=true to fail)", w)
+		}
+	}
+
+	if p.args.DryRunReport != "" && !p.args.DryRun {
+		return fmt.Errorf("dry_run_report requires dry_run=true")
+	}
+	// dry_run模式在最后输出差异，包括清单的变化
+	if p.args.DryRun {
+		defer func() {
+			if err == nil {
+				err = p.writeDryRunReport()
+			}
+		}()
+	}
+
 	// 如果只生成模型代码
 	if p.args.OnlyModel {
 		return p.handleModelCommand()
 	}
 
-	// 确定命令类型：优先使用显式指定，否则自动检测
-	// 自动检测基于项目目录结构，这对于脚手架工具是合理的
+	// 读取项目清单：存在时复用其中的目录与选项，并确定为update
+	manifest, err := config.LoadManifest(p.args.OutDir)
+	if err != nil {
+		return err
+	}
+	if manifest != nil {
+		if err := p.args.ApplyManifest(manifest); err != nil {
+			return err
+		}
+	}
+
+	// 确定命令类型：优先使用显式指定，其次根据项目清单，最后自动检测
+	// 自动检测基于项目目录结构，仅用于没有清单的旧项目
 	cmdType := p.args.CmdType
-	if cmdType == "" {
+	switch {
+	case cmdType != "":
+		p.logger.Infof("Using explicitly specified command type: %s", cmdType)
+	case manifest != nil:
+		cmdType = meta.CmdUpdate
+		p.args.CmdType = cmdType
+		p.logger.Infof("Found %s, using command type: %s", config.ManifestFile, cmdType)
+	default:
 		cmdType = p.autoDetectCommand()
 		p.args.CmdType = cmdType // 保存检测结果，避免后续重复检测
 		p.logger.Infof("Auto-detected command type: %s", cmdType)
+	}
+
+	// 检查版本兼容性，包括禁止降级
+	if err := p.checkVersions(manifest); err != nil {
+		return err
+	}
+
+	// 没有清单的项目在本次生成后写入清单，使后续运行的行为确定；
+	// 已有清单时记录本次显式给出的选项与插件版本，有变化才重写
+	record := manifest == nil
+	if manifest == nil {
+		manifest = p.args.NewManifest()
 	} else {
-		p.logger.Infof("Using explicitly specified command type: %s", cmdType)
+		record = p.args.UpdateManifest(manifest)
+	}
+	if record {
+		defer func() {
+			if err != nil {
+				return
+			}
+			if p.args.DryRun {
+				var content string
+				content, err = manifest.Encode()
+				p.stage(config.ManifestFile, content)
+				return
+			}
+			err = manifest.Persist(p.args.OutDir)
+		}()
 	}
 
 	switch cmdType {
 	case meta.CmdNew:
-		return p.handleNewCommand()
+		err = p.handleNewCommand()
 	case meta.CmdUpdate:
-		return p.handleUpdateCommand()
+		err = p.handleUpdateCommand()
 	default:
-		return p.handleNewCommand()
+		err = p.handleNewCommand()
 	}
+	return err
 }
 
 // parseArgs 解析插件参数
@@ -93,11 +167,13 @@ func (p *HZPlugin) parseArgs() error {
 	param := p.gen.Request.GetParameter()
 
 	p.args = &config.Argument{}
+	var params []string
 	if param != "" {
-		params := strings.Split(param, ",")
-		if err := p.args.Unpack(params); err != nil {
-			return err
-		}
+		params = strings.Split(param, ",")
+	}
+	// 即使没有参数也需要解析，以初始化默认值
+	if err := p.args.Unpack(params); err != nil {
+		return err
 	}
 
 	// 从proto文件的go_package选项提取go module
@@ -123,17 +199,8 @@ func (p *HZPlugin) extractGoModuleFromProto() error {
 				// 方法：去掉最后的package部分，通常是去掉 /biz/xxx 这样的路径
 
 				if p.args.Gomod == "" {
-					// 尝试智能提取模块根路径
-					// 如果包含 /biz/, 则提取到 /biz 之前的部分
-					moduleRoot := goPackage
-					if idx := strings.Index(goPackage, "/biz/"); idx != -1 {
-						moduleRoot = goPackage[:idx]
-					} else if idx := strings.LastIndex(goPackage, "/"); idx != -1 {
-						// 如果没有 /biz/，则去掉最后一个路径段
-						moduleRoot = goPackage[:idx]
-					}
-					p.args.Gomod = moduleRoot
-					p.logger.Debugf("Extracted go module root from proto: %s (from go_package: %s)", moduleRoot, goPackage)
+					p.args.Gomod = config.ModuleFromGoPackage(goPackage)
+					p.logger.Debugf("Extracted go module root from proto: %s (from go_package: %s)", p.args.Gomod, goPackage)
 				}
 				return nil
 			}
@@ -259,6 +326,12 @@ func (p *HZPlugin) generateLayout() error {
 		return err
 	}
 
+	if p.args.DryRun {
+		for _, file := range layoutGen.Files() {
+			p.stage(file.Path, file.Content)
+		}
+		return nil
+	}
 	return layoutGen.Persist()
 }
 
@@ -311,6 +384,12 @@ func (p *HZPlugin) generateHTTPCode() error {
 		HandlerByMethod:  p.args.HandlerByMethod,
 		SortRouter:       p.args.SortRouter,
 		CustomizePackage: p.args.CustomizePackage,
+		ClientCodec:      p.args.ClientCodec,
+		ProtoText:        p.args.ProtoText,
+		HandlerTests:     p.args.HandlerTest,
+		ClientMock:       p.args.ClientMock,
+		OTel:             p.args.OTel,
+		RoutesJSON:       p.args.RoutesJSON,
 	}
 
 	p.logger.Debugf("Created HTTP package generator: %+v", pkgGen)
@@ -321,7 +400,10 @@ func (p *HZPlugin) generateHTTPCode() error {
 	}
 
 	// 构建HTTP包数据
-	httpPkg := p.buildHTTPPackage()
+	httpPkg, err := p.buildHTTPPackage()
+	if err != nil {
+		return err
+	}
 	p.logger.Debugf("Built HTTP package: %+v", httpPkg)
 
 	// 生成代码
@@ -332,6 +414,43 @@ func (p *HZPlugin) generateHTTPCode() error {
 
 	p.logger.Debugf("Generated %d files", len(files))
 
+	// 只生成一次的文件（如handler测试脚手架）已存在时归用户所有，不再覆盖
+	kept := files[:0]
+	for _, file := range files {
+		if _, err := os.Stat(filepath.Join(p.args.OutDir, filepath.FromSlash(file.Path))); file.Once && err == nil {
+			p.logger.Debugf("Keeping existing %s", file.Path)
+			continue
+		}
+		kept = append(kept, file)
+	}
+	files = kept
+
+	// 格式化生成的代码，语法错误在此处以文件为单位报告，而不是到 go build 时才发现
+	for _, file := range files {
+		if !strings.HasSuffix(file.Path, ".go") {
+			continue
+		}
+		formatted, err := format.Source([]byte(file.Content))
+		if err != nil {
+			return fmt.Errorf("format %s: %w", file.Path, err)
+		}
+		file.Content = string(formatted)
+	}
+
+	if p.args.Verify {
+		if err := p.verifyGeneratedCode(files); err != nil {
+			return err
+		}
+	}
+
+	// dry_run模式下不返回文件，protoc因此不会写入
+	if p.args.DryRun {
+		for _, file := range files {
+			p.stage(file.Path, file.Content)
+		}
+		return nil
+	}
+
 	// 将生成的文件添加到protogen响应
 	for _, file := range files {
 		p.logger.Debugf("Adding file: %s", file.Path)
@@ -340,8 +459,11 @@ func (p *HZPlugin) generateHTTPCode() error {
 		// 例如: biz/handler/SayHello.go -> github.com/example/project/biz/handler
 		goImportPath := p.buildGoImportPath(file.Path)
 
+		// 原样写入，使文件内容与dry_run比较的内容一致
 		g := p.gen.NewGeneratedFile(file.Path, protogen.GoImportPath(goImportPath))
-		g.P(file.Content)
+		if _, err := g.Write([]byte(file.Content)); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -355,7 +477,7 @@ func (p *HZPlugin) generateClientCode() error {
 }
 
 // buildHTTPPackage 构建HTTP包数据结构
-func (p *HZPlugin) buildHTTPPackage() *generator.HTTPPackage {
+func (p *HZPlugin) buildHTTPPackage() (*generator.HTTPPackage, error) {
 	// 获取 model 包路径（从 proto 的 go_package 中获取）
 	modelPkg := ""
 	for _, file := range p.gen.Files {
@@ -381,22 +503,65 @@ func (p *HZPlugin) buildHTTPPackage() *generator.HTTPPackage {
 	for _, file := range p.gen.Files {
 		if file.Generate {
 			for _, service := range file.Services {
+				envelope, err := p.serviceEnvelope(service)
+				if err != nil {
+					return nil, err
+				}
+
 				svc := &generator.Service{
 					Name:          string(service.GoName),
 					Methods:       []*generator.HTTPMethod{},
 					ClientMethods: []*generator.ClientMethod{},
 					Models:        []*model.Model{},
 					BaseDomain:    p.args.BaseDomain,
+					Envelope:      envelope,
+					Comment:       protoComment(service.Comments),
+					Deprecated:    service.Desc.Options().(*descriptorpb.ServiceOptions).GetDeprecated(),
 				}
 
 				// 提取方法信息
 				for _, method := range service.Methods {
+					bindings, err := methodBindings(service, method)
+					if err != nil {
+						return nil, err
+					}
 					httpMethod := &generator.HTTPMethod{
 						Name:         string(method.GoName),
-						HTTPMethod:   "POST", // 默认POST，可以通过注释配置
-						Path:         "/" + string(service.GoName) + "/" + string(method.GoName),
+						Bindings:     bindings,
 						RequestType:  string(method.Input.GoIdent.GoName),
 						ResponseType: string(method.Output.GoIdent.GoName),
+						ServerStream: method.Desc.IsStreamingServer(),
+						ClientStream: method.Desc.IsStreamingClient(),
+						Comment:      protoComment(method.Comments),
+						Deprecated:   svc.Deprecated || method.Desc.Options().(*descriptorpb.MethodOptions).GetDeprecated(),
+						ProtoName:    string(method.Desc.FullName()),
+					}
+					if httpMethod.Auth, err = methodAuth(service, method); err != nil {
+						return nil, err
+					}
+					if httpMethod.Timeout, httpMethod.MaxBodySize, err = methodLimits(method); err != nil {
+						return nil, err
+					}
+					if httpMethod.Deprecation, httpMethod.Sunset, err = methodDeprecation(method); err != nil {
+						return nil, err
+					}
+					if httpMethod.Deprecation != 0 {
+						httpMethod.Deprecated = true
+					}
+					// 客户端流式与双向流式方法只能通过WebSocket传输，握手请求必须为GET，且没有请求可以绑定路径变量
+					if httpMethod.ClientStream {
+						if !p.args.WebSocket {
+							return nil, fmt.Errorf("method %s is %s but no transport supports it, enable WebSocket with websocket=true",
+								method.Desc.FullName(), streamingKind(method))
+						}
+						if err := websocketBindings(method, bindings); err != nil {
+							return nil, err
+						}
+					}
+					httpMethod.HTTPMethod, httpMethod.Path = bindings[0].HTTPMethod, bindings[0].Path
+					if p.args.HandlerTest {
+						httpMethod.SampleRequest = sampleLiteral(method.Input, method.Input.GoIdent.GoImportPath)
+						httpMethod.SamplePath = samplePath(bindings[0], method.Input)
 					}
 					svc.Methods = append(svc.Methods, httpMethod)
 				}
@@ -406,7 +571,148 @@ func (p *HZPlugin) buildHTTPPackage() *generator.HTTPPackage {
 		}
 	}
 
-	return httpPkg
+	// 收集错误原因枚举
+	errorEnums, err := p.buildErrorEnums()
+	if err != nil {
+		return nil, err
+	}
+	httpPkg.ErrorEnums = errorEnums
+
+	return httpPkg, nil
+}
+
+// protoComment 合并proto的分离注释、前置注释与尾随注释，段落之间以空行分隔，每行不含注释符
+func protoComment(set protogen.CommentSet) string {
+	var blocks []string
+	for _, c := range append(append(set.LeadingDetached, set.Leading), set.Trailing) {
+		if text := strings.TrimSuffix(string(c), "\n"); strings.TrimSpace(text) != "" {
+			blocks = append(blocks, text)
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// methodAuth 方法的认证要求，方法选项 (hz.auth) 优先于服务选项 (hz.default_auth)
+// 未声明或 scheme 为 none 时方法公开，返回nil
+func methodAuth(service *protogen.Service, method *protogen.Method) (*generator.Auth, error) {
+	var auth *hzpb.Auth
+	switch {
+	case proto.HasExtension(method.Desc.Options(), hzpb.E_Auth):
+		auth = proto.GetExtension(method.Desc.Options(), hzpb.E_Auth).(*hzpb.Auth)
+	case proto.HasExtension(service.Desc.Options(), hzpb.E_DefaultAuth):
+		auth = proto.GetExtension(service.Desc.Options(), hzpb.E_DefaultAuth).(*hzpb.Auth)
+	}
+	if auth == nil || auth.GetScheme() == "none" {
+		return nil, nil
+	}
+	if auth.GetScheme() == "" {
+		return nil, fmt.Errorf("method %s: authentication requires a scheme, e.g. \"bearer\", or \"none\" for a public method",
+			method.Desc.FullName())
+	}
+	for _, scope := range auth.GetScopes() {
+		if strings.TrimSpace(scope) == "" {
+			return nil, fmt.Errorf("method %s: empty authentication scope", method.Desc.FullName())
+		}
+	}
+	return &generator.Auth{Scheme: auth.GetScheme(), Scopes: auth.GetScopes()}, nil
+}
+
+// methodLimits 解析方法选项 (hz.timeout) 与 (hz.max_body_size)
+func methodLimits(method *protogen.Method) (time.Duration, int64, error) {
+	opts := method.Desc.Options()
+	var timeout time.Duration
+	if value := proto.GetExtension(opts, hzpb.E_Timeout).(string); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return 0, 0, fmt.Errorf("method %s: invalid (hz.timeout) %q, expected a positive duration like 500ms or 5s",
+				method.Desc.FullName(), value)
+		}
+		timeout = d
+	}
+	maxBodySize := proto.GetExtension(opts, hzpb.E_MaxBodySize).(int64)
+	switch {
+	case maxBodySize < 0:
+		return 0, 0, fmt.Errorf("method %s: invalid (hz.max_body_size) %d, expected a positive number of bytes",
+			method.Desc.FullName(), maxBodySize)
+	case maxBodySize > 0 && method.Desc.IsStreamingClient():
+		return 0, 0, fmt.Errorf("method %s: (hz.max_body_size) does not apply to %s methods, whose messages are WebSocket frames",
+			method.Desc.FullName(), streamingKind(method))
+	}
+	return timeout, maxBodySize, nil
+}
+
+// methodDeprecation 解析方法选项 (hz.deprecation) 与 (hz.sunset)，
+// 返回Deprecation响应头使用的Unix时间（秒）与Sunset响应头使用的HTTP日期，未设置时为零值
+func methodDeprecation(method *protogen.Method) (int64, string, error) {
+	opts := method.Desc.Options()
+	deprecation, err := optionDate(proto.GetExtension(opts, hzpb.E_Deprecation).(string), false)
+	if err != nil {
+		return 0, "", fmt.Errorf("method %s: invalid (hz.deprecation) %v", method.Desc.FullName(), err)
+	}
+	sunset, err := optionDate(proto.GetExtension(opts, hzpb.E_Sunset).(string), true)
+	if err != nil {
+		return 0, "", fmt.Errorf("method %s: invalid (hz.sunset) %v", method.Desc.FullName(), err)
+	}
+	// RFC 9745: Sunset不应早于Deprecation
+	if !deprecation.IsZero() && !sunset.IsZero() && sunset.Before(deprecation) {
+		return 0, "", fmt.Errorf("method %s: (hz.sunset) is earlier than (hz.deprecation)", method.Desc.FullName())
+	}
+
+	var unix int64
+	if !deprecation.IsZero() {
+		unix = deprecation.Unix()
+	}
+	var httpDate string
+	if !sunset.IsZero() {
+		httpDate = sunset.UTC().Format(http.TimeFormat)
+	}
+	return unix, httpDate, nil
+}
+
+// optionDate 解析 2006-01-02 格式的日期或 RFC 3339 时间，value为空时返回零值
+// 日期在endOfDay时取当天的最后一秒（如下线日期当天仍可用），否则取当天开始
+func optionDate(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q, expected a date like 2026-12-31 or an RFC 3339 timestamp", value)
+	}
+	if endOfDay {
+		day = day.Add(24*time.Hour - time.Second)
+	}
+	return day, nil
+}
+
+// streamingKind 方法的流式类型描述，用于错误信息
+func streamingKind(method *protogen.Method) string {
+	if method.Desc.IsStreamingServer() {
+		return "bidi-streaming"
+	}
+	return "client-streaming"
+}
+
+// serviceEnvelope 确定服务的JSON响应包装格式
+// 服务选项 (hz.envelope) 优先于插件参数 envelope，"none" 表示不包装
+func (p *HZPlugin) serviceEnvelope(service *protogen.Service) (string, error) {
+	envelope := p.args.Envelope
+	if proto.HasExtension(service.Desc.Options(), hzpb.E_Envelope) {
+		envelope = proto.GetExtension(service.Desc.Options(), hzpb.E_Envelope).(string)
+	}
+	if envelope == "" {
+		return "", nil
+	}
+	if err := generator.CheckEnvelope(envelope); err != nil {
+		return "", fmt.Errorf("service %s: %w", service.Desc.FullName(), err)
+	}
+	if envelope == "none" {
+		return "", nil
+	}
+	return envelope, nil
 }
 
 // buildGoImportPath 根据文件路径构建Go import路径
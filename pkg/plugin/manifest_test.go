@@ -0,0 +1,79 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ca-x/protoc-gen-go-hz/pkg/config"
+)
+
+// TestManifestUpdates 每次update显式给出的选项都写回清单，之后不带选项的运行按清单生成
+func TestManifestUpdates(t *testing.T) {
+	tc := goldenCase{name: "manifest", protoset: "greeter", files: []string{"greeter.proto"}}
+	out := t.TempDir()
+	req := loadRequest(t, tc)
+	run := func(options string) map[string]string {
+		t.Helper()
+		param := "out_dir=" + out
+		if options != "" {
+			param += "," + options
+		}
+		req.Parameter = &param
+		files, _ := runPlugin(t, req)
+		return files
+	}
+	options := func() map[string]string {
+		t.Helper()
+		m, err := config.LoadManifest(out)
+		if err != nil || m == nil {
+			t.Fatalf("LoadManifest: %v, %v", m, err)
+		}
+		return m.Options
+	}
+
+	run("client_dir=biz/client")
+	if got, want := options(), map[string]string{"client_dir": "biz/client"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("after new: options = %v, want %v", got, want)
+	}
+	run("otel=true")
+	if got, want := options(), map[string]string{"client_dir": "biz/client", "otel": "true"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("after first update: options = %v, want %v", got, want)
+	}
+	run("envelope=code_msg_data,otel=false")
+	want := map[string]string{"client_dir": "biz/client", "otel": "false", "envelope": "code_msg_data"}
+	if got := options(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("after second update: options = %v, want %v", got, want)
+	}
+
+	// 不带选项的运行复用清单中的选项
+	files := run("")
+	if _, ok := files["biz/client/client.go"]; !ok {
+		t.Error("client_dir from the manifest was not applied")
+	}
+	if _, ok := files["biz/telemetry/telemetry.go"]; ok {
+		t.Error("otel=false from the second update was not applied")
+	}
+	if !strings.Contains(files["biz/handler/SayHello.go"], "codec.EnvelopeCodeMsgData") {
+		t.Error("envelope from the second update was not applied")
+	}
+	if got := options(); !reflect.DeepEqual(got, want) {
+		t.Errorf("a run without options changed the manifest: %v", got)
+	}
+}
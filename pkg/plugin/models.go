@@ -0,0 +1,76 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	// protoc-gen-go 没有提供可导入的生成API，这是本模块唯一使用其内部包的地方，
+	// 输出与同版本 protoc-gen-go 命令逐字节一致由 TestGenerateModels 保证
+	gengo "google.golang.org/protobuf/cmd/protoc-gen-go/internal_gengo"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// gengoProtobufVersion 经 TestGenerateModels 验证的 google.golang.org/protobuf 版本，
+// 与go.mod中固定的版本一致
+const gengoProtobufVersion = "v1.36.10"
+
+// GenerateModels 在进程内运行 protoc-gen-go，为请求中需要生成的文件生成模型代码，
+// 请求的参数（如 module=...）与传给 protoc-gen-go 的 --go_opt 含义相同。
+// 链接的 google.golang.org/protobuf 不是验证过的版本时返回错误，而不是生成可能不同的代码
+func GenerateModels(req *pluginpb.CodeGeneratorRequest) (*pluginpb.CodeGeneratorResponse, error) {
+	if v := linkedProtobufVersion(); v != "" && v != gengoProtobufVersion {
+		return nil, fmt.Errorf("protoc-gen-go-hz was built with google.golang.org/protobuf %s, "+
+			"but generating models in process is only verified with %s; run protoc-gen-go instead", v, gengoProtobufVersion)
+	}
+	gen, err := protogen.Options{}.New(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range gen.Files {
+		if f.Generate {
+			gengo.GenerateFile(gen, f)
+		}
+	}
+	gen.SupportedFeatures = gengo.SupportedFeatures
+	gen.SupportedEditionsMinimum = gengo.SupportedEditionsMinimum
+	gen.SupportedEditionsMaximum = gengo.SupportedEditionsMaximum
+	resp := gen.Response()
+	if resp.Error != nil {
+		return nil, fmt.Errorf("protoc-gen-go: %s", resp.GetError())
+	}
+	return resp, nil
+}
+
+// linkedProtobufVersion 构建信息中 google.golang.org/protobuf 的版本，没有构建信息时为空
+func linkedProtobufVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "google.golang.org/protobuf" {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// TestGenerateModels GenerateModels 依赖 protoc-gen-go 的内部包，升级 google.golang.org/protobuf
+// 后其行为可能悄然改变：与 go.mod 中同版本 protoc-gen-go 命令的输出逐字节比较
+func TestGenerateModels(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds protoc-gen-go")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	protocGenGo := filepath.Join(t.TempDir(), "protoc-gen-go")
+	cmd := exec.Command(goBin, "build", "-o", protocGenGo, "google.golang.org/protobuf/cmd/protoc-gen-go")
+	cmd.Env = append(cmd.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("build protoc-gen-go: %v\n%s", err, out)
+	}
+
+	seen := map[string]bool{}
+	for _, tc := range goldenCases {
+		if seen[tc.protoset] {
+			continue
+		}
+		seen[tc.protoset] = true
+		for _, param := range []string{"", "module=example.com"} {
+			t.Run(tc.protoset+"/"+param, func(t *testing.T) {
+				req := loadRequest(t, tc)
+				req.Parameter = proto.String(param)
+
+				got, err := GenerateModels(proto.Clone(req).(*pluginpb.CodeGeneratorRequest))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				in, err := proto.Marshal(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				cmd := exec.Command(protocGenGo)
+				cmd.Stdin = bytes.NewReader(in)
+				out, err := cmd.Output()
+				if err != nil {
+					t.Fatalf("protoc-gen-go: %v", err)
+				}
+				want := &pluginpb.CodeGeneratorResponse{}
+				if err := proto.Unmarshal(out, want); err != nil {
+					t.Fatal(err)
+				}
+				if want.Error != nil {
+					t.Fatalf("protoc-gen-go: %s", want.GetError())
+				}
+
+				if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+					t.Fatalf("GenerateModels no longer matches protoc-gen-go, internal_gengo changed; "+
+						"update models.go for the new google.golang.org/protobuf version (-protoc-gen-go +GenerateModels):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+// TestGengoProtobufVersion go.mod固定的、构建时链接的 google.golang.org/protobuf 版本与
+// gengoProtobufVersion 一致，升级protobuf时必须同时确认 TestGenerateModels
+func TestGengoProtobufVersion(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("..", "..", "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var required string
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "require "))
+		if len(fields) >= 2 && fields[0] == "google.golang.org/protobuf" {
+			required = fields[1]
+		}
+	}
+	if required != gengoProtobufVersion {
+		t.Errorf("go.mod requires google.golang.org/protobuf %q, gengoProtobufVersion is %q", required, gengoProtobufVersion)
+	}
+	if v := linkedProtobufVersion(); v != gengoProtobufVersion {
+		t.Errorf("linked google.golang.org/protobuf %q, gengoProtobufVersion is %q", v, gengoProtobufVersion)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRuntime 运行生成代码：golden用例的输出与protoc-gen-go模型写入临时module，
+// 与 testdata/runtime/<name> 中的测试一起执行 go test，覆盖类型检查发现不了的行为，
+// 如线上的JSON格式与遥测数据。依赖从本模块的go.mod与模块缓存解析，不访问网络
+func TestRuntime(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test on generated projects")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range goldenCases {
+		testdir := filepath.Join("testdata", "runtime", tc.name)
+		if _, err := os.Stat(testdir); err != nil {
+			continue
+		}
+		t.Run(tc.name, func(t *testing.T) {
+			req := loadRequest(t, tc)
+			files, gomod := runPlugin(t, req)
+			models, err := generateModels(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dir := t.TempDir()
+			for name, content := range files {
+				writeFile(t, filepath.Join(dir, filepath.FromSlash(name)), content)
+			}
+			for name, content := range models {
+				writeFile(t, filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(name, gomod+"/"))), content)
+			}
+			err = filepath.WalkDir(testdir, func(p string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				b, err := os.ReadFile(p)
+				if err != nil {
+					return err
+				}
+				rel, _ := filepath.Rel(testdir, p)
+				writeFile(t, filepath.Join(dir, rel), string(b))
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeFile(t, filepath.Join(dir, "go.mod"), "module "+gomod+"\n\ngo 1.23\n\n"+
+				"require github.com/ca-x/protoc-gen-go-hz v0.0.0\n\n"+
+				"replace github.com/ca-x/protoc-gen-go-hz => "+filepath.ToSlash(root)+"\n")
+			sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeFile(t, filepath.Join(dir, "go.sum"), string(sum))
+
+			cmd := exec.Command(goBin, "test", "-count=1", "./...")
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("go test: %v\n%s", err, out)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// sampleMaxDepth 示例请求中嵌套消息的最大深度
+const sampleMaxDepth = 3
+
+// sampleLiteral 根据消息结构生成示例请求的Go字面量，模型包以 model 限定
+// 每个字段取一个示例值：字符串取字段名，数值取1，枚举取第一个非零值；
+// 不属于模型包的消息与枚举（如标准类型）以及超过深度的嵌套消息保持零值
+func sampleLiteral(message *protogen.Message, modelImport protogen.GoImportPath) string {
+	s := &sampler{modelImport: modelImport, visiting: map[*protogen.Message]bool{}}
+	return "&" + s.message(message, 0)
+}
+
+type sampler struct {
+	modelImport protogen.GoImportPath
+	visiting    map[*protogen.Message]bool
+}
+
+// message 消息的复合字面量，不带取地址符
+func (s *sampler) message(message *protogen.Message, depth int) string {
+	s.visiting[message] = true
+	defer delete(s.visiting, message)
+
+	var b strings.Builder
+	b.WriteString("model." + message.GoIdent.GoName + "{")
+	seenOneofs := map[*protogen.Oneof]bool{}
+	for _, field := range message.Fields {
+		if oneof := field.Oneof; oneof != nil && !oneof.Desc.IsSynthetic() {
+			// oneof只设置第一个可用的字段
+			if seenOneofs[oneof] {
+				continue
+			}
+			value, ok := s.value(field, depth)
+			if !ok {
+				continue
+			}
+			seenOneofs[oneof] = true
+			fmt.Fprintf(&b, "\n%s: &model.%s{%s: %s},", oneof.GoName, field.GoIdent.GoName, field.GoName, value)
+			continue
+		}
+
+		value, ok := s.fieldValue(field, depth)
+		if ok {
+			fmt.Fprintf(&b, "\n%s: %s,", field.GoName, value)
+		}
+	}
+	if strings.HasSuffix(b.String(), ",") {
+		b.WriteString("\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// fieldValue 字段的示例值，处理重复字段、map与显式presence的标量
+func (s *sampler) fieldValue(field *protogen.Field, depth int) (string, bool) {
+	switch {
+	case field.Desc.IsMap():
+		key, value := field.Message.Fields[0], field.Message.Fields[1]
+		k, ok := s.value(key, depth)
+		if !ok {
+			return "", false
+		}
+		v, ok := s.value(value, depth)
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("map[%s]%s{%s: %s}", s.goType(key), s.goType(value), k, v), true
+	case field.Desc.IsList():
+		v, ok := s.value(field, depth)
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("[]%s{%s}", s.goType(field), v), true
+	}
+
+	v, ok := s.value(field, depth)
+	if !ok {
+		return "", false
+	}
+	// 显式presence的标量字段（proto3 optional、proto2、editions）为指针，bytes除外
+	if field.Desc.HasPresence() && field.Message == nil && field.Desc.Kind() != protoreflect.BytesKind {
+		if field.Enum != nil {
+			return v + ".Enum()", true
+		}
+		return fmt.Sprintf("proto.%s(%s)", presenceHelper(field.Desc.Kind()), v), true
+	}
+	return v, true
+}
+
+// value 单个元素的示例值
+func (s *sampler) value(field *protogen.Field, depth int) (string, bool) {
+	switch field.Desc.Kind() {
+	case protoreflect.BoolKind:
+		return "true", true
+	case protoreflect.StringKind:
+		return fmt.Sprintf("%q", field.Desc.Name()), true
+	case protoreflect.BytesKind:
+		return fmt.Sprintf("[]byte(%q)", field.Desc.Name()), true
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return "1.5", true
+	case protoreflect.EnumKind:
+		if field.Enum.GoIdent.GoImportPath != s.modelImport {
+			return "", false
+		}
+		value := field.Enum.Values[0]
+		if len(field.Enum.Values) > 1 {
+			value = field.Enum.Values[1]
+		}
+		return "model." + value.GoIdent.GoName, true
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if field.Message.GoIdent.GoImportPath != s.modelImport || depth+1 >= sampleMaxDepth || s.visiting[field.Message] {
+			return "", false
+		}
+		return "&" + s.message(field.Message, depth+1), true
+	default:
+		return "1", true
+	}
+}
+
+// goType 重复字段与map元素的Go类型
+func (s *sampler) goType(field *protogen.Field) string {
+	switch field.Desc.Kind() {
+	case protoreflect.EnumKind:
+		return "model." + field.Enum.GoIdent.GoName
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return "*model." + field.Message.GoIdent.GoName
+	case protoreflect.BytesKind:
+		return "[]byte"
+	}
+	return strings.ToLower(presenceHelper(field.Desc.Kind()))
+}
+
+// presenceHelper 标量类型对应的 proto 包辅助函数名，如 proto.Int32
+func presenceHelper(kind protoreflect.Kind) string {
+	switch kind {
+	case protoreflect.BoolKind:
+		return "Bool"
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return "Int32"
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return "Uint32"
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return "Int64"
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return "Uint64"
+	case protoreflect.FloatKind:
+		return "Float32"
+	case protoreflect.DoubleKind:
+		return "Float64"
+	case protoreflect.StringKind:
+		return "String"
+	}
+	return ""
+}
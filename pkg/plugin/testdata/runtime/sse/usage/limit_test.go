@@ -0,0 +1,158 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+
+	handler "example.com/watch/biz/handler"
+	model "example.com/watch/biz/model"
+	router "example.com/watch/biz/router"
+)
+
+// Get declares (hz.max_body_size) = 1024.
+const limit = 1024
+
+type watcher struct {
+	handler.UnimplementedWatcherService
+}
+
+func (watcher) Get(_ context.Context, req *model.WatchRequest) (*model.Event, error) {
+	return &model.Event{Topic: req.Topic}, nil
+}
+
+func init() {
+	handler.SetWatcherService(watcher{})
+}
+
+// countingReader counts the bytes the handler reads from a body stream.
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddInt64(c.n, int64(n))
+	return n, err
+}
+
+// unreadable fails the test if the handler reads the body.
+type unreadable struct{ t *testing.T }
+
+func (u unreadable) Read([]byte) (int, error) {
+	u.t.Error("the body was read although Content-Length exceeds the limit")
+	return 0, errors.New("unexpected read")
+}
+
+// withBodyStream registers the routes behind a middleware that replaces the
+// request body with a stream, as server.WithStreamBody does.
+func withBodyStream(stream func() (io.Reader, int)) *server.Hertz {
+	h := server.New()
+	h.Use(func(ctx context.Context, c *app.RequestContext) {
+		r, size := stream()
+		c.Request.SetBodyStream(r, size)
+		c.Next(ctx)
+	})
+	router.Register(h)
+	return h
+}
+
+func perform(h *server.Hertz, body string) *ut.ResponseRecorder {
+	return ut.PerformRequest(h.Engine, "POST", "/Watcher/Get",
+		&ut.Body{Body: bytes.NewBufferString(body), Len: len(body)},
+		ut.Header{Key: "Content-Type", Value: "application/json"})
+}
+
+func TestContentLengthRejectedWithoutReading(t *testing.T) {
+	h := withBodyStream(func() (io.Reader, int) { return unreadable{t}, limit + 1 })
+	if w := perform(h, "{}"); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status %d, want 413: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestStreamedBodyLimited(t *testing.T) {
+	var read int64
+	h := withBodyStream(func() (io.Reader, int) {
+		// A chunked body has no Content-Length.
+		return countingReader{strings.NewReader(`{"topic":"` + strings.Repeat("x", 1<<20) + `"}`), &read}, -1
+	})
+	if w := perform(h, "{}"); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status %d, want 413: %s", w.Code, w.Body.String())
+	}
+	if read > limit+1 {
+		t.Errorf("the handler read %d bytes of the body, want at most %d", read, limit+1)
+	}
+}
+
+func TestStreamedBodyWithinLimit(t *testing.T) {
+	h := withBodyStream(func() (io.Reader, int) {
+		return strings.NewReader(`{"topic":"news"}`), -1
+	})
+	w := perform(h, "{}")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	if body := w.Body.String(); !strings.Contains(body, `"topic":"news"`) {
+		t.Errorf("body = %s, want the streamed request decoded", body)
+	}
+}
+
+// TestStreamBodyServer runs a server with server.WithStreamBody, where the
+// handler sees the body as a stream.
+func TestStreamBodyServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	h := server.New(server.WithHostPorts(addr), server.WithStreamBody(true))
+	router.Register(h)
+	go h.Run()
+	defer h.Shutdown(context.Background())
+	for i := 0; i < 100; i++ {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for _, tt := range []struct {
+		name string
+		body io.Reader
+		want int
+	}{
+		{"small", strings.NewReader(`{"topic":"news"}`), http.StatusOK},
+		{"content-length", strings.NewReader(`{"topic":"` + strings.Repeat("x", 2*limit) + `"}`), http.StatusRequestEntityTooLarge},
+		// io.MultiReader hides the length, so the body is sent chunked. It is
+		// kept small enough to be written in full before the server answers
+		// and closes the connection, which would otherwise fail the write.
+		{"chunked", io.MultiReader(strings.NewReader(`{"topic":"` + strings.Repeat("x", 2*limit) + `"}`)), http.StatusRequestEntityTooLarge},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post("http://"+addr+"/Watcher/Get", "application/json", tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				b, _ := io.ReadAll(resp.Body)
+				t.Errorf("status %d, want %d: %s", resp.StatusCode, tt.want, b)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package usage
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+
+	codec "example.com/users/biz/codec"
+	model "example.com/users/biz/model"
+)
+
+func newQueryContext(uri, body string) *app.RequestContext {
+	c := app.NewContext(0)
+	c.Request.SetRequestURI(uri)
+	c.Request.Header.SetContentTypeBytes([]byte("application/json"))
+	c.Request.SetBodyString(body)
+	return c
+}
+
+// BindQuery sets a message field only when the query reaches one of its
+// fields, so an unknown nested key keeps the message unset.
+func TestBindQueryPresence(t *testing.T) {
+	tests := []struct {
+		query string
+		page  bool // whether page is set
+		after bool // whether page.after is set
+	}{
+		{query: "?page.bogus=1"},
+		{query: "?page.after.bogus=1"},
+		{query: "?page.size=0", page: true},
+		{query: "?page.after.id=u1", page: true, after: true},
+		{query: "?page.size=2&page.after.bogus=1", page: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var req model.GetUserRequest
+			if err := codec.BindQuery(newQueryContext("/"+tt.query, ""), &req); err != nil {
+				t.Fatal(err)
+			}
+			if got := req.Page != nil; got != tt.page {
+				t.Errorf("page set = %v, want %v", got, tt.page)
+			}
+			if got := req.GetPage().GetAfter() != nil; got != tt.after {
+				t.Errorf("page.after set = %v, want %v", got, tt.after)
+			}
+		})
+	}
+
+	// A value that does not parse leaves the message unset as well.
+	var req model.GetUserRequest
+	if err := codec.BindQuery(newQueryContext("/?page.size=x", ""), &req); err == nil {
+		t.Error("BindQuery accepted page.size=x")
+	}
+	if req.Page != nil {
+		t.Error("page set by an invalid value")
+	}
+}
+
+// DecodeField leaves the body field unset when the body is empty.
+func TestDecodeFieldPresence(t *testing.T) {
+	tests := []struct {
+		body string
+		page bool
+	}{
+		{body: ""},
+		{body: "{}", page: true},
+		{body: `{"size":3}`, page: true},
+	}
+	for _, tt := range tests {
+		var req model.GetUserRequest
+		if err := codec.DecodeField(newQueryContext("/", tt.body), &req, "page"); err != nil {
+			t.Fatalf("body %q: %v", tt.body, err)
+		}
+		if got := req.Page != nil; got != tt.page {
+			t.Errorf("body %q: page set = %v, want %v", tt.body, got, tt.page)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	auth "example.com/users/biz/auth"
+	errors "example.com/users/biz/errors"
+	handler "example.com/users/biz/handler"
+	model "example.com/users/biz/model"
+	router "example.com/users/biz/router"
+	telemetry "example.com/users/biz/telemetry"
+)
+
+type users struct {
+	handler.UnimplementedUsersService
+}
+
+func (users) GetUser(_ context.Context, req *model.GetUserRequest) (*model.User, error) {
+	if req.Id == "boom" {
+		return nil, errors.New(errors.Internal, "boom")
+	}
+	return &model.User{Id: req.Id}, nil
+}
+
+type admin struct {
+	handler.UnimplementedAdminService
+}
+
+func (admin) DeleteUser(_ context.Context, req *model.GetUserRequest) (*model.User, error) {
+	return nil, errors.ErrorUserNotFound("user %s not found", req.Id)
+}
+
+func init() {
+	handler.SetUsersService(users{})
+	handler.SetAdminService(admin{})
+	auth.SetAuthenticator(auth.AuthenticatorFunc(func(context.Context, *app.RequestContext, *auth.Route) error {
+		return nil
+	}))
+}
+
+func TestTelemetry(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	if err := telemetry.Configure(telemetry.Config{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	h := server.New()
+	router.Register(h)
+	for _, r := range []struct {
+		path, body string
+		status     int
+	}{
+		{"/Users/GetUser", `{"id":"u1"}`, 200},
+		{"/Users/GetUser", `{"id":"boom"}`, 500},
+		{"/Admin/DeleteUser", `{"id":"u2"}`, 404},
+	} {
+		w := ut.PerformRequest(h.Engine, "POST", r.path,
+			&ut.Body{Body: bytes.NewBufferString(r.body), Len: len(r.body)},
+			ut.Header{Key: "Content-Type", Value: "application/json"})
+		if w.Code != r.status {
+			t.Fatalf("POST %s %s: status %d, want %d: %s", r.path, r.body, w.Code, r.status, w.Body.String())
+		}
+	}
+
+	// spans: named after the proto method, error.type for failed calls,
+	// an error status only for server errors
+	ended := spans.Ended()
+	if len(ended) != 3 {
+		t.Fatalf("got %d spans, want 3", len(ended))
+	}
+	for i, want := range []struct {
+		name      string
+		status    int64
+		errorType string
+		code      codes.Code
+	}{
+		{"users.v1.Users/GetUser", 200, "", codes.Unset},
+		{"users.v1.Users/GetUser", 500, "INTERNAL", codes.Error},
+		{"users.v1.Admin/DeleteUser", 404, "NOT_FOUND", codes.Unset},
+	} {
+		span := ended[i]
+		if span.Name() != want.name {
+			t.Errorf("span %d: name %q, want %q", i, span.Name(), want.name)
+		}
+		attrs := attribute.NewSet(span.Attributes()...)
+		if v, _ := attrs.Value("http.response.status_code"); v.AsInt64() != want.status {
+			t.Errorf("span %d: http.response.status_code = %v, want %d", i, v.Emit(), want.status)
+		}
+		if v, _ := attrs.Value("error.type"); v.AsString() != want.errorType {
+			t.Errorf("span %d: error.type = %q, want %q", i, v.AsString(), want.errorType)
+		}
+		if v, _ := attrs.Value("rpc.method"); v.AsString() != want.name {
+			t.Errorf("span %d: rpc.method = %q, want %q", i, v.AsString(), want.name)
+		}
+		if span.Status().Code != want.code {
+			t.Errorf("span %d: status %v, want %v", i, span.Status().Code, want.code)
+		}
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	metrics := map[string]metricdata.Metrics{}
+	for _, sm := range rm.ScopeMetrics {
+		if sm.Scope.Name != telemetry.InstrumentationName {
+			continue
+		}
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m
+		}
+	}
+
+	sum := func(name string) map[string]int64 {
+		t.Helper()
+		data, ok := metrics[name].Data.(metricdata.Sum[int64])
+		if !ok {
+			t.Fatalf("%s: got %T, want an int64 sum", name, metrics[name].Data)
+		}
+		counts := map[string]int64{}
+		for _, dp := range data.DataPoints {
+			method, _ := dp.Attributes.Value("rpc.method")
+			status, _ := dp.Attributes.Value("http.response.status_code")
+			counts[method.AsString()+" "+status.Emit()] += dp.Value
+		}
+		return counts
+	}
+	if got, want := sum("hz.server.requests"), map[string]int64{
+		"users.v1.Users/GetUser 200":    1,
+		"users.v1.Users/GetUser 500":    1,
+		"users.v1.Admin/DeleteUser 404": 1,
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("hz.server.requests = %v, want %v", got, want)
+	}
+	if got, want := sum("hz.server.errors"), map[string]int64{
+		"users.v1.Users/GetUser 500":    1,
+		"users.v1.Admin/DeleteUser 404": 1,
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("hz.server.errors = %v, want %v", got, want)
+	}
+
+	duration := metrics["hz.server.duration"]
+	if duration.Unit != "s" {
+		t.Errorf("hz.server.duration unit %q, want s", duration.Unit)
+	}
+	hist, ok := duration.Data.(metricdata.Histogram[float64])
+	if !ok {
+		t.Fatalf("hz.server.duration: got %T, want a float64 histogram", duration.Data)
+	}
+	var count uint64
+	buckets := []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
+	for _, dp := range hist.DataPoints {
+		count += dp.Count
+		if !reflect.DeepEqual(dp.Bounds, buckets) {
+			t.Errorf("hz.server.duration bounds = %v, want second buckets %v", dp.Bounds, buckets)
+		}
+		if dp.Sum <= 0 || dp.Sum > 1 {
+			t.Errorf("hz.server.duration sum = %v, want seconds", dp.Sum)
+		}
+	}
+	if count != 3 {
+		t.Errorf("hz.server.duration count = %d, want 3", count)
+	}
+}
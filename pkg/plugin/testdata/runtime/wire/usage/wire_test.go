@@ -0,0 +1,123 @@
+package usage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	hclient "github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"google.golang.org/protobuf/proto"
+
+	client "example.com/wire/biz/client"
+	handler "example.com/wire/biz/handler"
+	model "example.com/wire/biz/model"
+	router "example.com/wire/biz/router"
+)
+
+// The bodies must follow the protobuf JSON mapping: int64 as a string,
+// enums by name, oneof members inline and lowerCamelCase field names.
+const wireJSON = `{"big":"1152921504606846976","color":"COLOR_RED","named":{"name":"n"},"displayName":"d"}`
+
+type echo struct {
+	handler.UnimplementedWireService
+}
+
+func (echo) Echo(_ context.Context, m *model.Message) (*model.Message, error) {
+	return m, nil
+}
+
+func init() {
+	handler.SetWireService(echo{})
+}
+
+func TestJSONMapping(t *testing.T) {
+	h := server.New()
+	router.Register(h)
+
+	w := ut.PerformRequest(h.Engine, "POST", "/Wire/Echo",
+		&ut.Body{Body: bytes.NewBufferString(wireJSON), Len: len(wireJSON)},
+		ut.Header{Key: "Content-Type", Value: "application/json"})
+	if w.Code != 200 {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	var got, want map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(wireJSON), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("response body = %s, want %s", w.Body.String(), wireJSON)
+	}
+}
+
+// The body of a binding with body "*" is the whole request, so query
+// parameters must not override or add to it.
+func TestBodyIgnoresQuery(t *testing.T) {
+	h := server.New()
+	router.Register(h)
+
+	w := ut.PerformRequest(h.Engine, "POST", "/Wire/Echo?display_name=q&big=5&number=3",
+		&ut.Body{Body: bytes.NewBufferString(wireJSON), Len: len(wireJSON)},
+		ut.Header{Key: "Content-Type", Value: "application/json"})
+	if w.Code != 200 {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	var got, want map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(wireJSON), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("response body = %s, want %s", w.Body.String(), wireJSON)
+	}
+}
+
+func TestClientRoundTrip(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	h := server.New(server.WithHostPorts(addr))
+	router.Register(h)
+	go h.Run()
+	defer h.Shutdown(context.Background())
+	for i := 0; i < 100; i++ {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	hc, err := hclient.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := client.NewWireClient(hc, client.WithBaseURL("http://"+addr))
+	req := &model.Message{
+		Big:         1 << 60,
+		Color:       model.Color_COLOR_RED,
+		Kind:        &model.Message_Named{Named: &model.Named{Name: "n"}},
+		DisplayName: "d",
+	}
+	resp, err := c.Echo(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(resp, req) {
+		t.Errorf("Echo() = %v, want %v", resp, req)
+	}
+}
@@ -0,0 +1,320 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/ca-x/protoc-gen-go-hz/pkg/generator"
+)
+
+// verifyGeneratedCode 对生成的包做类型检查（verify=true）
+// 模型包由 protoc-gen-go 在内存中生成，其余依赖通过 go list -export 在 out_dir 所在的 module 中加载，
+// 错误信息附带出错代码所属的 proto 方法
+func (p *HZPlugin) verifyGeneratedCode(files []*generator.GeneratedFile) error {
+	fset := token.NewFileSet()
+	pkgs := map[string][]*ast.File{}
+	for _, file := range files {
+		if !strings.HasSuffix(file.Path, ".go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file.Path, file.Content, parser.SkipObjectResolution)
+		if err != nil {
+			return fmt.Errorf("verify: %v", err)
+		}
+		importPath := p.buildGoImportPath(file.Path)
+		// 外部测试包单独检查
+		if strings.HasSuffix(f.Name.Name, "_test") {
+			importPath += "_test"
+		}
+		pkgs[importPath] = append(pkgs[importPath], f)
+	}
+
+	models, err := generateModels(p.gen.Request)
+	if err != nil {
+		return fmt.Errorf("verify: generate models: %w", err)
+	}
+	for name, content := range models {
+		f, err := parser.ParseFile(fset, name, content, parser.SkipObjectResolution)
+		if err != nil {
+			return fmt.Errorf("verify: %v", err)
+		}
+		pkgs[path.Dir(name)] = append(pkgs[path.Dir(name)], f)
+	}
+
+	typeErrs, err := checkPackages(p.args.OutDir, fset, pkgs)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+	if len(typeErrs) == 0 {
+		p.logger.Infof("Verified %d generated packages", len(pkgs))
+		return nil
+	}
+
+	methods := p.protoMethods()
+	msgs := make([]string, 0, len(typeErrs))
+	for _, e := range typeErrs {
+		msg := e.Error()
+		if method := originMethod(e, pkgs, methods); method != "" {
+			msg += " (method " + method + ")"
+		}
+		msgs = append(msgs, msg)
+	}
+	return fmt.Errorf("verify: generated code does not compile:\n\t%s", strings.Join(msgs, "\n\t"))
+}
+
+// protoMethods 方法的Go名称 -> 服务Go名称 -> proto全名
+func (p *HZPlugin) protoMethods() map[string]map[string]string {
+	methods := map[string]map[string]string{}
+	for _, file := range p.gen.Files {
+		if !file.Generate {
+			continue
+		}
+		for _, service := range file.Services {
+			for _, method := range service.Methods {
+				name := string(method.GoName)
+				if methods[name] == nil {
+					methods[name] = map[string]string{}
+				}
+				methods[name][string(service.GoName)] = string(method.Desc.FullName())
+			}
+		}
+	}
+	return methods
+}
+
+// originMethod 根据出错位置所在的函数、方法或接口方法推断对应的 proto 方法
+// 同名方法存在于多个服务时，以接收者类型或文件名中的服务名区分
+func originMethod(err types.Error, pkgs map[string][]*ast.File, methods map[string]map[string]string) string {
+	pos := err.Fset.Position(err.Pos)
+	var file *ast.File
+	for _, files := range pkgs {
+		for _, f := range files {
+			if err.Fset.Position(f.Pos()).Filename == pos.Filename {
+				file = f
+			}
+		}
+	}
+	if file == nil {
+		return ""
+	}
+
+	var name, owner string
+	for _, decl := range file.Decls {
+		if decl.Pos() > err.Pos || err.Pos >= decl.End() {
+			continue
+		}
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			name = d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				owner = receiverName(d.Recv.List[0].Type)
+			}
+		case *ast.GenDecl:
+			ast.Inspect(d, func(n ast.Node) bool {
+				if n == nil || n.Pos() > err.Pos || err.Pos >= n.End() {
+					return false
+				}
+				switch n := n.(type) {
+				case *ast.TypeSpec:
+					owner = n.Name.Name
+				case *ast.Field:
+					if len(n.Names) > 0 {
+						name = n.Names[0].Name
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	candidates := methods[name]
+	if len(candidates) == 0 {
+		return ""
+	}
+	hint := strings.ToLower(owner + " " + path.Base(pos.Filename))
+	services := make([]string, 0, len(candidates))
+	for service := range candidates {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	for _, service := range services {
+		if strings.Contains(hint, strings.ToLower(service)) {
+			return candidates[service]
+		}
+	}
+	if len(services) == 1 {
+		return candidates[services[0]]
+	}
+	return ""
+}
+
+func receiverName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(e.X)
+	case *ast.IndexExpr:
+		return receiverName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+// generateModels 用 protoc-gen-go 生成请求中各文件的模型代码，返回文件名（含import path）-> 内容
+func generateModels(req *pluginpb.CodeGeneratorRequest) (map[string]string, error) {
+	req = proto.Clone(req).(*pluginpb.CodeGeneratorRequest)
+	req.Parameter = nil
+	resp, err := GenerateModels(req)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string, len(resp.GetFile()))
+	for _, f := range resp.GetFile() {
+		files[f.GetName()] = f.GetContent()
+	}
+	return files, nil
+}
+
+// checkPackages 对 pkgs（import path -> 文件）做类型检查，返回类型错误
+// 其余依赖的导出数据由 go list -export 在 dir 中构建
+func checkPackages(dir string, fset *token.FileSet, pkgs map[string][]*ast.File) ([]types.Error, error) {
+	deps := map[string]bool{}
+	for _, files := range pkgs {
+		for _, f := range files {
+			for _, spec := range f.Imports {
+				dep, _ := strconv.Unquote(spec.Path.Value)
+				// unsafe没有导出数据，由导入器直接提供
+				if _, local := pkgs[dep]; !local && dep != "unsafe" {
+					deps[dep] = true
+				}
+			}
+		}
+	}
+	exports, err := exportData(dir, deps)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for dep := range deps {
+		if _, ok := exports[dep]; !ok {
+			missing = append(missing, dep)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("cannot load %s from the module in %q, add them to go.mod (e.g. go mod tidy) or disable verify",
+			strings.Join(missing, ", "), dir)
+	}
+
+	imp := &memImporter{
+		fset:   fset,
+		files:  pkgs,
+		loaded: map[string]*types.Package{},
+		gc: importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+			file, ok := exports[path]
+			if !ok {
+				return nil, fmt.Errorf("no export data for %s", path)
+			}
+			return os.Open(file)
+		}),
+	}
+	importPaths := make([]string, 0, len(pkgs))
+	for importPath := range pkgs {
+		importPaths = append(importPaths, importPath)
+	}
+	sort.Strings(importPaths)
+	for _, importPath := range importPaths {
+		_, _ = imp.Import(importPath)
+	}
+	return imp.errs, nil
+}
+
+// exportData 用 go list 构建依赖的导出数据，返回 import path -> 导出数据文件
+// 无法加载的包没有导出数据
+func exportData(dir string, deps map[string]bool) (map[string]string, error) {
+	if len(deps) == 0 {
+		return nil, nil
+	}
+	args := []string{"list", "-e", "-export", "-deps", "-f", "{{if .Export}}{{.ImportPath}}={{.Export}}{{end}}"}
+	for dep := range deps {
+		args = append(args, dep)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list -export: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	exports := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if path, file, ok := strings.Cut(line, "="); ok {
+			exports[path] = file
+		}
+	}
+	return exports, nil
+}
+
+// memImporter 检查内存中的包，其余包交给导出数据导入器
+// 有错误的包仍会缓存，避免依赖它的包重复报告同样的错误
+type memImporter struct {
+	fset   *token.FileSet
+	files  map[string][]*ast.File
+	loaded map[string]*types.Package
+	gc     types.Importer
+	errs   []types.Error
+}
+
+func (m *memImporter) Import(path string) (*types.Package, error) {
+	if pkg, ok := m.loaded[path]; ok {
+		return pkg, nil
+	}
+	files, ok := m.files[path]
+	if !ok {
+		return m.gc.Import(path)
+	}
+
+	conf := types.Config{
+		Importer: m,
+		Error: func(err error) {
+			if e, ok := err.(types.Error); ok {
+				m.errs = append(m.errs, e)
+			}
+		},
+	}
+	pkg, _ := conf.Check(path, m.fset, files, nil)
+	m.loaded[path] = pkg
+	return pkg, nil
+}
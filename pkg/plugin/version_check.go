@@ -0,0 +1,146 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gv "github.com/hashicorp/go-version"
+
+	"github.com/ca-x/protoc-gen-go-hz/pkg/config"
+	"github.com/ca-x/protoc-gen-go-hz/pkg/version"
+)
+
+// 版本检查模式
+const (
+	versionCheckWarn  = "warn"  // 版本不匹配时警告，降级时报错
+	versionCheckError = "error" // 版本不匹配与降级均报错
+	versionCheckOff   = "off"   // 不检查
+)
+
+// checkVersions 检查 protoc、protoc-gen-go 以及清单记录的插件版本与当前运行环境是否兼容
+func (p *HZPlugin) checkVersions(manifest *config.Manifest) error {
+	mode := p.args.VersionCheck
+	if mode == "" {
+		mode = versionCheckWarn
+	}
+	if mode == versionCheckOff {
+		return nil
+	}
+
+	current := mustVersion(version.Version)
+
+	// 禁止用旧版本插件覆盖新版本生成的代码
+	if manifest != nil && manifest.PluginVersion != "" {
+		recorded, err := gv.NewVersion(manifest.PluginVersion)
+		if err != nil {
+			return fmt.Errorf("invalid protoc-gen-go-hz version %q in %s: %v", manifest.PluginVersion, config.ManifestFile, err)
+		}
+		if current.LessThan(recorded) {
+			return fmt.Errorf("project was generated by protoc-gen-go-hz %s, refusing to downgrade it with %s; upgrade the plugin or set version_check=off",
+				manifest.PluginVersion, version.Version)
+		}
+		if current.GreaterThan(recorded) {
+			if err := p.versionMismatch(mode, "project was generated by protoc-gen-go-hz %s, regenerating with %s",
+				manifest.PluginVersion, version.Version); err != nil {
+				return err
+			}
+		}
+	}
+
+	// protoc 版本，来自 CodeGeneratorRequest.compiler_version，buf 等工具可能不设置
+	if cv := p.gen.Request.GetCompilerVersion(); cv != nil {
+		protoc := fmt.Sprintf("v%d.%d.%d", cv.GetMajor(), cv.GetMinor(), cv.GetPatch())
+		if mustVersion(protoc).LessThan(mustVersion(version.MinProtocVersion)) {
+			if err := p.versionMismatch(mode, "protoc %s is older than the minimum supported %s",
+				protoc, version.MinProtocVersion); err != nil {
+				return err
+			}
+		}
+	}
+
+	// protoc-gen-go 版本，取自模型目录中已生成的 .pb.go 文件头
+	if pbVersion, file := p.protocGenGoVersion(); pbVersion != "" {
+		v, err := gv.NewVersion(pbVersion)
+		if err == nil && v.LessThan(mustVersion(version.ProtocGenGoVersion)) {
+			if err := p.versionMismatch(mode, "%s was generated by protoc-gen-go %s, older than the minimum supported %s",
+				file, pbVersion, version.ProtocGenGoVersion); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// versionMismatch 按检查模式报告版本不匹配
+func (p *HZPlugin) versionMismatch(mode, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if mode == versionCheckError {
+		return fmt.Errorf("%s (version_check=error)", msg)
+	}
+	p.logger.Warn(msg)
+	return nil
+}
+
+// protocGenGoVersion 返回模型目录中第一个 .pb.go 文件记录的 protoc-gen-go 版本及该文件路径
+func (p *HZPlugin) protocGenGoVersion() (string, string) {
+	var found, foundFile string
+	root := filepath.Join(p.args.OutDir, p.args.ModelDir)
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || found != "" {
+			return fs.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".pb.go") {
+			return nil
+		}
+		if v := readProtocGenGoVersion(path); v != "" {
+			found, foundFile = v, path
+			return fs.SkipAll
+		}
+		return nil
+	})
+	return found, foundFile
+}
+
+// readProtocGenGoVersion 读取 .pb.go 文件头中的 "// 	protoc-gen-go vX.Y.Z" 行
+func readProtocGenGoVersion(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for i := 0; i < 10 && s.Scan(); i++ {
+		fields := strings.Fields(strings.TrimPrefix(s.Text(), "//"))
+		if len(fields) == 2 && fields[0] == "protoc-gen-go" {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// mustVersion 解析编译期已知合法的版本号
+func mustVersion(v string) *gv.Version {
+	return gv.Must(gv.NewVersion(v))
+}
@@ -0,0 +1,208 @@
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	"github.com/ca-x/protoc-gen-go-hz/pkg/config"
+	"github.com/ca-x/protoc-gen-go-hz/pkg/version"
+)
+
+func TestCheckVersions(t *testing.T) {
+	const oldPbGo = "// Code generated by protoc-gen-go. DO NOT EDIT.\n// versions:\n// \tprotoc-gen-go v1.28.1\n// \tprotoc        v3.21.12\n\npackage model\n"
+	const newPbGo = "// Code generated by protoc-gen-go. DO NOT EDIT.\n// versions:\n// \tprotoc-gen-go v1.36.6\n// \tprotoc        v5.29.3\n\npackage model\n"
+	oldProtoc := &pluginpb.Version{Major: proto.Int32(3), Minor: proto.Int32(19), Patch: proto.Int32(4)}
+	newProtoc := &pluginpb.Version{Major: proto.Int32(5), Minor: proto.Int32(29), Patch: proto.Int32(3)}
+
+	tests := []struct {
+		name     string
+		mode     string
+		recorded string            // 清单中记录的插件版本，为空时不写清单
+		protoc   *pluginpb.Version // CodeGeneratorRequest.compiler_version
+		pbGo     string            // 模型目录中 .pb.go 文件的内容
+		err      string            // 期望错误包含的内容
+		warnings []string          // 期望警告包含的内容
+	}{
+		{
+			name:   "up to date",
+			protoc: newProtoc,
+			pbGo:   newPbGo,
+		},
+		{
+			name:   "no compiler version",
+			protoc: nil,
+		},
+		{
+			name:     "old protoc warns",
+			protoc:   oldProtoc,
+			warnings: []string{"protoc v3.19.4 is older than the minimum supported " + version.MinProtocVersion},
+		},
+		{
+			name:   "old protoc error",
+			mode:   versionCheckError,
+			protoc: oldProtoc,
+			err:    "protoc v3.19.4 is older than the minimum supported " + version.MinProtocVersion + " (version_check=error)",
+		},
+		{
+			name:   "old protoc off",
+			mode:   versionCheckOff,
+			protoc: oldProtoc,
+		},
+		{
+			name:     "old protoc-gen-go warns",
+			pbGo:     oldPbGo,
+			warnings: []string{"was generated by protoc-gen-go v1.28.1, older than the minimum supported " + version.ProtocGenGoVersion},
+		},
+		{
+			name: "old protoc-gen-go error",
+			mode: versionCheckError,
+			pbGo: oldPbGo,
+			err:  "was generated by protoc-gen-go v1.28.1",
+		},
+		{
+			name: "pb.go without version header",
+			mode: versionCheckError,
+			pbGo: "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage model\n",
+		},
+		{
+			name:     "older plugin recorded warns",
+			recorded: "v0.1.0",
+			warnings: []string{"project was generated by protoc-gen-go-hz v0.1.0, regenerating with " + version.Version},
+		},
+		{
+			name:     "older plugin recorded error",
+			mode:     versionCheckError,
+			recorded: "v0.1.0",
+			err:      "project was generated by protoc-gen-go-hz v0.1.0",
+		},
+		{
+			name:     "same plugin recorded",
+			mode:     versionCheckError,
+			recorded: version.Version,
+		},
+		{
+			name:     "downgrade refused in warn mode",
+			recorded: "v99.0.0",
+			err:      "refusing to downgrade it with " + version.Version,
+		},
+		{
+			name:     "downgrade allowed when off",
+			mode:     versionCheckOff,
+			recorded: "v99.0.0",
+		},
+		{
+			name:     "invalid recorded version",
+			recorded: "latest",
+			err:      `invalid protoc-gen-go-hz version "latest" in .hz`,
+		},
+		{
+			name:     "several warnings",
+			recorded: "v0.1.0",
+			protoc:   oldProtoc,
+			pbGo:     oldPbGo,
+			warnings: []string{"protoc-gen-go-hz v0.1.0", "protoc v3.19.4", "protoc-gen-go v1.28.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var manifest *config.Manifest
+			if tt.recorded != "" {
+				m := (&config.Argument{}).NewManifest()
+				m.PluginVersion = tt.recorded
+				if err := m.Persist(dir); err != nil {
+					t.Fatal(err)
+				}
+				var err error
+				if manifest, err = config.LoadManifest(dir); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.pbGo != "" {
+				writeFile(t, filepath.Join(dir, "biz", "model", "demo", "demo.pb.go"), tt.pbGo)
+			}
+
+			logger, hook := logtest.NewNullLogger()
+			p := &HZPlugin{
+				gen:    &protogen.Plugin{Request: &pluginpb.CodeGeneratorRequest{CompilerVersion: tt.protoc}},
+				args:   &config.Argument{OutDir: dir, ModelDir: "biz/model", VersionCheck: tt.mode},
+				logger: logger,
+			}
+			err := p.checkVersions(manifest)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("checkVersions error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkVersions: %v", err)
+			}
+
+			var warnings []string
+			for _, e := range hook.AllEntries() {
+				if e.Level == logrus.WarnLevel {
+					warnings = append(warnings, e.Message)
+				}
+			}
+			if len(warnings) != len(tt.warnings) {
+				t.Fatalf("warnings = %q, want %d", warnings, len(tt.warnings))
+			}
+			for i, w := range tt.warnings {
+				if !strings.Contains(warnings[i], w) {
+					t.Errorf("warning %d = %q, want %q", i, warnings[i], w)
+				}
+			}
+		})
+	}
+}
+
+func TestReadProtocGenGoVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"header", "// Code generated by protoc-gen-go. DO NOT EDIT.\n// versions:\n// \tprotoc-gen-go v1.36.6\n// \tprotoc        (unknown)\n", "v1.36.6"},
+		{"spaces", "// versions:\n//   protoc-gen-go   v1.31.0\n", "v1.31.0"},
+		{"missing", "package model\n", ""},
+		{"beyond header", strings.Repeat("//\n", 10) + "// \tprotoc-gen-go v1.31.0\n", ""},
+		{"other generator", "// \tprotoc-gen-go-grpc v1.3.0\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "x.pb.go")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if got := readProtocGenGoVersion(path); got != tt.want {
+				t.Errorf("readProtocGenGoVersion = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
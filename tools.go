@@ -0,0 +1,35 @@
+//go:build tools
+
+/*
+ * Copyright 2022 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+// 生成的代码依赖、但本模块自身不导入的包。记录在go.mod中，使测试能够编译与
+// 运行生成的代码：编译检查从模块的导出数据加载这些包，testdata/runtime 中的
+// 测试在临时module中复用本模块的go.mod依赖版本。
+import (
+	// 生成的WebSocket传输
+	_ "github.com/gorilla/websocket"
+	_ "github.com/hertz-contrib/websocket"
+	// 生成的telemetry包，runtime测试使用SDK读取追踪与指标
+	_ "go.opentelemetry.io/otel"
+	_ "go.opentelemetry.io/otel/sdk/metric"
+	_ "go.opentelemetry.io/otel/sdk/trace/tracetest"
+	// 生成的errors包
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
+	_ "google.golang.org/genproto/googleapis/rpc/status"
+)